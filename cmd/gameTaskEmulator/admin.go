@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/grpcretry"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/sink"
+)
+
+// newAdminFlagSet returns a flag set for an administration subcommand with
+// the flags that locate and reach the Cloud Tasks queue registered on config.
+// Call finishAdminFlags after parsing.
+func newAdminFlagSet(name, usage string, config *Config, emulatorHost *string) *flag.FlagSet {
+	flags := flag.NewFlagSet(os.Args[0]+" "+name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s %s\n\nFlags:\n", os.Args[0], usage)
+		flags.PrintDefaults()
+	}
+
+	flags.StringVar(&config.ProjectID, "project", "localproject", "GCP Project ID")
+	flags.StringVar(&config.Location, "location", "us-south1", "GCP Location")
+	flags.StringVar(&config.QueueName, "queue", "gameschedule", "Task Queue name")
+	flags.BoolVar(&config.Production, "prod", false, "Use the production queue instead of the local emulator")
+	flags.StringVar(emulatorHost, "emulator", "", "Cloud Tasks emulator host (default: localhost:8123 or CLOUD_TASKS_EMULATOR env var)")
+	flags.DurationVar(&config.DialTimeout, "dial-timeout", 10*time.Second, "How long to wait for the Cloud Tasks connection and health check")
+	flags.BoolVar(&config.TLS, "tls", false, "Connect to Cloud Tasks over TLS, verifying the server against the system roots")
+	flags.StringVar(&config.TLSCAFile, "tls-ca-file", "", "PEM CA bundle to verify the Cloud Tasks server with (implies -tls)")
+	flags.IntVar(&config.RPCAttempts, "rpc-attempts", grpcretry.DefaultPolicy().MaxAttempts, "Attempts per Cloud Tasks RPC when it fails with Unavailable, DeadlineExceeded or ResourceExhausted")
+	flags.StringVar(&config.LogFormat, "log-format", "text", "Log output format: text or json")
+	flags.StringVar(&config.LogLevel, "log-level", "warn", "Minimum log level: debug, info, warn or error")
+	return flags
+}
+
+// parseAdminFlags parses an administration subcommand's flags and sets up
+// logging; invalid flags exit with ExitConfigError
+func parseAdminFlags(flags *flag.FlagSet, args []string, config *Config, emulatorHost *string) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(ExitOK)
+		}
+		os.Exit(ExitConfigError)
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments: %v\n", flags.Args())
+		flags.Usage()
+		os.Exit(ExitConfigError)
+	}
+
+	logger, err := newLogger(os.Stderr, config.LogFormat, config.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	slog.SetDefault(logger)
+
+	config.EmulatorHost = resolveEmulatorHost(*emulatorHost)
+	if config.TLSCAFile != "" {
+		config.TLS = true
+	}
+	if config.DialTimeout <= 0 {
+		fatal(ExitConfigError, "-dial-timeout must be positive")
+	}
+	if config.RPCAttempts < 1 {
+		fatal(ExitConfigError, "-rpc-attempts must be at least 1")
+	}
+}
+
+// connectAdmin connects to the configured queue; an unreachable service exits
+// with ExitTotalFailure
+func connectAdmin(ctx context.Context, config *Config) (*sink.CloudTasks, func()) {
+	client, conn, err := connectToTasksService(ctx, config)
+	if err != nil {
+		fatal(ExitTotalFailure, "Failed to connect to Cloud Tasks", "error", err)
+	}
+	return sink.NewCloudTasks(client, config.ProjectID, config.Location, config.QueueName), func() { conn.Close() }
+}
+
+// subcommand splits the action off a subcommand's arguments, printing usage
+// and exiting when it is missing or unknown
+func subcommand(name string, args []string, actions ...string) (string, []string) {
+	if len(args) > 0 {
+		for _, action := range actions {
+			if args[0] == action {
+				return action, args[1:]
+			}
+		}
+		if args[0] != "-h" && args[0] != "-help" && args[0] != "--help" {
+			fmt.Fprintf(os.Stderr, "Error: unknown %s command %q\n", name, args[0])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Usage: %s %s <command> [flags]\n\nCommands:\n", os.Args[0], name)
+	for _, action := range actions {
+		fmt.Fprintf(os.Stderr, "  %s\n", action)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s %s <command> -h' for the command's flags.\n", os.Args[0], name)
+	if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		os.Exit(ExitOK)
+	}
+	os.Exit(ExitConfigError)
+	return "", nil
+}
+
+// --- tasks ---
+
+// runTasksCommand implements "tasks list", "tasks cancel" and "tasks run"
+func runTasksCommand(args []string) {
+	action, args := subcommand("tasks", args, "list", "cancel", "run")
+
+	config := &Config{}
+	var emulatorHost, timezone, taskName string
+	var gameID int
+	var flags *flag.FlagSet
+	switch action {
+	case "list":
+		flags = newAdminFlagSet("tasks list", "tasks list [flags]", config, &emulatorHost)
+		flags.StringVar(&timezone, "tz", "", "IANA time zone to show schedule times in (default: host local time zone)")
+	case "cancel":
+		flags = newAdminFlagSet("tasks cancel", "tasks cancel -game ID [flags]", config, &emulatorHost)
+		flags.IntVar(&gameID, "game", 0, "NHL game ID whose tasks are cancelled (required)")
+	case "run":
+		flags = newAdminFlagSet("tasks run", "tasks run (-game ID | -task NAME) [flags]", config, &emulatorHost)
+		flags.IntVar(&gameID, "game", 0, "NHL game ID whose tasks are dispatched now")
+		flags.StringVar(&taskName, "task", "", "Name of a task to dispatch now, as shown by 'tasks list'")
+	}
+	parseAdminFlags(flags, args, config, &emulatorHost)
+
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			fatal(ExitConfigError, "Invalid -tz", "error", err)
+		}
+	}
+	switch {
+	case action == "cancel" && gameID <= 0:
+		fatal(ExitConfigError, "tasks cancel needs -game")
+	case action == "run" && (gameID > 0) == (taskName != ""):
+		fatal(ExitConfigError, "tasks run needs exactly one of -game and -task")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	queue, closeConn := connectAdmin(ctx, config)
+	defer closeConn()
+
+	tasks, err := queue.List(ctx)
+	if err != nil {
+		fatal(ExitTotalFailure, "Failed to list tasks", "error", err)
+	}
+
+	switch action {
+	case "list":
+		writeTaskTable(os.Stdout, tasks, location)
+	case "cancel":
+		matched := gameTasks(tasks, gameID)
+		if len(matched) == 0 {
+			fmt.Printf("No tasks found for game %d\n", gameID)
+			return
+		}
+		for _, task := range matched {
+			if err := queue.Cancel(ctx, task.Name); err != nil {
+				fatal(ExitTotalFailure, "Failed to cancel task", "task", task.Name, "error", err)
+			}
+			fmt.Printf("Cancelled %s\n", path.Base(task.Name))
+		}
+	case "run":
+		matched := []sink.Task{{Name: taskName}}
+		if gameID > 0 {
+			if matched = gameTasks(tasks, gameID); len(matched) == 0 {
+				fmt.Printf("No tasks found for game %d\n", gameID)
+				return
+			}
+		}
+		for _, task := range matched {
+			if err := queue.Run(ctx, task.Name); err != nil {
+				fatal(ExitTotalFailure, "Failed to run task", "task", task.Name, "error", err)
+			}
+			fmt.Printf("Dispatched %s\n", path.Base(task.Name))
+		}
+	}
+}
+
+// taskSummary is what the admin commands show of a task's payload
+type taskSummary struct {
+	GameID  string
+	Matchup string // AWAY @ HOME
+	Kind    string // game or reminder
+}
+
+// summarizeTask decodes a task's TaskPayload or ReminderPayload body; bodies
+// that are neither are summarized with placeholders
+func summarizeTask(task sink.Task) taskSummary {
+	var payload struct {
+		Type string   `json:"type"`
+		Game GameInfo `json:"game"`
+	}
+	if err := json.Unmarshal(task.Body, &payload); err != nil || payload.Game.ID == "" {
+		return taskSummary{GameID: "-", Matchup: "-", Kind: "unknown"}
+	}
+
+	summary := taskSummary{
+		GameID:  payload.Game.ID,
+		Matchup: payload.Game.AwayTeam.Abbrev + " @ " + payload.Game.HomeTeam.Abbrev,
+		Kind:    "game",
+	}
+	if payload.Type == ReminderPayloadType {
+		summary.Kind = "reminder"
+	}
+	return summary
+}
+
+// gameTasks returns the tasks whose payload is for the given game
+func gameTasks(tasks []sink.Task, gameID int) []sink.Task {
+	var matched []sink.Task
+	for _, task := range tasks {
+		if summarizeTask(task).GameID == strconv.Itoa(gameID) {
+			matched = append(matched, task)
+		}
+	}
+	return matched
+}
+
+// writeTaskTable writes the tasks as a table, in schedule order
+func writeTaskTable(w io.Writer, tasks []sink.Task, location *time.Location) {
+	if len(tasks) == 0 {
+		fmt.Fprintln(w, "No tasks queued")
+		return
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "TASK\tGAME\tMATCHUP\tKIND\tSCHEDULED")
+	for _, task := range tasks {
+		summary := summarizeTask(task)
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", path.Base(task.Name), summary.GameID, summary.Matchup, summary.Kind,
+			task.ScheduleTime.In(location).Format("2006-01-02 15:04 MST"))
+	}
+	table.Flush()
+}
+
+// --- queue ---
+
+// runQueueCommand implements "queue pause", "queue resume" and "queue purge"
+func runQueueCommand(args []string) {
+	action, args := subcommand("queue", args, "pause", "resume", "purge")
+
+	config := &Config{}
+	var emulatorHost string
+	var confirmed bool
+	flags := newAdminFlagSet("queue "+action, "queue "+action+" [flags]", config, &emulatorHost)
+	if action == "purge" {
+		flags.BoolVar(&confirmed, "yes", false, "Confirm deleting every task on the queue (required)")
+	}
+	parseAdminFlags(flags, args, config, &emulatorHost)
+	if action == "purge" && !confirmed {
+		fatal(ExitConfigError, "queue purge deletes every task on the queue; pass -yes to confirm")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	queue, closeConn := connectAdmin(ctx, config)
+	defer closeConn()
+
+	var err error
+	var done string
+	switch action {
+	case "pause":
+		_, err = queue.Pause(ctx)
+		done = "Paused"
+	case "resume":
+		_, err = queue.Resume(ctx)
+		done = "Resumed"
+	case "purge":
+		_, err = queue.Purge(ctx)
+		done = "Purged"
+	}
+	if err != nil {
+		fatal(ExitTotalFailure, "Queue command failed", "command", action, "error", err)
+	}
+	fmt.Printf("%s queue %s\n", done, queue.QueuePath())
+}
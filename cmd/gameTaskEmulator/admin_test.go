@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/sink"
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+)
+
+func TestWriteTaskTable(t *testing.T) {
+	game := newTestGames(1)[0]
+	gameBody, _ := json.Marshal(TaskPayload{Game: newTaskGameInfo(game)})
+	reminderBody, _ := json.Marshal(ReminderPayload{Type: ReminderPayloadType, Game: newTaskGameInfo(game)})
+	at := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	writeTaskTable(&out, []sink.Task{
+		{Name: "tasks/1", Body: reminderBody, ScheduleTime: at.Add(-30 * time.Minute)},
+		{Name: "tasks/2", Body: gameBody, ScheduleTime: at},
+		{Name: "tasks/3", Body: []byte("not json"), ScheduleTime: at},
+	}, time.UTC)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("table has %d lines, want a header and 3 rows:\n%s", len(lines), out.String())
+	}
+	want := [][]string{
+		{"TASK", "GAME", "MATCHUP", "KIND", "SCHEDULED"},
+		{"1", "2024020001", "DAL @ BOS", "reminder", "2025-01-15 18:30 UTC"},
+		{"2", "2024020001", "DAL @ BOS", "game", "2025-01-15 19:00 UTC"},
+		{"3", "-", "-", "unknown", "2025-01-15 19:00 UTC"},
+	}
+	for i, line := range lines {
+		for _, cell := range want[i] {
+			if !strings.Contains(line, cell) {
+				t.Errorf("line %d = %q, want it to contain %q", i, line, cell)
+			}
+		}
+	}
+
+	out.Reset()
+	writeTaskTable(&out, nil, time.UTC)
+	if out.String() != "No tasks queued\n" {
+		t.Errorf("empty table = %q, want a no-tasks message", out.String())
+	}
+}
+
+func TestAdminCommandsAgainstEmulator(t *testing.T) {
+	dispatched := make(chan struct{}, 10)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dispatched <- struct{}{}
+	}))
+	defer target.Close()
+
+	config := newTestConfig()
+	config.LocalMode = false
+	config.HostURL = target.URL
+	config.ReminderURL = target.URL
+	config.ReminderLead = 30 * time.Minute
+	config.EmulatorHost = startEmulator(t)
+	config.ProjectID, config.Location, config.QueueName = "localproject", "us-south1", "gameschedule"
+	config.DialTimeout = 5 * time.Second
+	config.RPCAttempts = 1
+
+	ctx := context.Background()
+	taskSink, closeSink, err := newTaskSink(ctx, config)
+	if err != nil {
+		t.Fatalf("newTaskSink() returned error: %v", err)
+	}
+	defer closeSink()
+
+	games := newTestGames(2)
+	if _, err := processGames(ctx, taskSink, config, games); err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+
+	queue, closeConn := connectAdmin(ctx, config)
+	defer closeConn()
+
+	tasks, err := queue.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	first := gameTasks(tasks, games[0].ID)
+	if len(first) != 2 {
+		t.Fatalf("gameTasks() found %d tasks for game %d, want its game task and reminder", len(first), games[0].ID)
+	}
+
+	// Cancelling one game leaves the other's tasks queued
+	for _, task := range first {
+		if err := queue.Cancel(ctx, task.Name); err != nil {
+			t.Fatalf("Cancel() returned error: %v", err)
+		}
+	}
+	tasks, _ = queue.List(ctx)
+	if len(tasks) != 2 || len(gameTasks(tasks, games[1].ID)) != 2 {
+		t.Errorf("after cancelling game %d the queue holds %d tasks, want game %d's 2", games[0].ID, len(tasks), games[1].ID)
+	}
+
+	// A paused queue still dispatches on request
+	paused, err := queue.Pause(ctx)
+	if err != nil || paused.State != taskspb.Queue_PAUSED {
+		t.Fatalf("Pause() = %v, %v, want a paused queue", paused, err)
+	}
+	if err := queue.Run(ctx, tasks[0].Name); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	select {
+	case <-dispatched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the forced dispatch")
+	}
+
+	if _, err := queue.Purge(ctx); err != nil {
+		t.Fatalf("Purge() returned error: %v", err)
+	}
+	if tasks, _ := queue.List(ctx); len(tasks) != 0 {
+		t.Errorf("queue holds %d tasks after purge, want 0", len(tasks))
+	}
+}
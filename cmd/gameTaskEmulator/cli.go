@@ -0,0 +1,461 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/history"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/notification"
+)
+
+// version is the release version, set at build time with
+// -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// command is a gameTaskEmulator subcommand
+type command struct {
+	Name    string
+	Summary string
+	Run     func(args []string)
+}
+
+// commands lists the subcommands in the order the usage shows them
+var commands = []command{
+	{"schedule", "Fetch a day's games and create their tracking tasks (the default)", runSchedule},
+	{"games", "Preview a day's matching games and when their tasks would run", runGamesCommand},
+	{"teams", "List the NHL team city codes and IDs accepted by -teams", runTeamsCommand},
+	{"history", "Show the scheduling decisions earlier runs recorded", runHistoryCommand},
+	{"tasks", "List, cancel or run queued tasks", runTasksCommand},
+	{"queue", "Pause, resume or purge the task queue", runQueueCommand},
+	{"notify", "Send a test notification to the configured Discord webhooks", runNotifyCommand},
+	{"emulator", "Serve a local Cloud Tasks emulator", runEmulator},
+	{"version", "Print version and build information", runVersionCommand},
+}
+
+// resolveCommand picks the subcommand named by the first argument. Arguments
+// that start with a flag select schedule, so "gameTaskEmulator -local -today"
+// keeps working; ok is false when no command was given or it is unknown.
+func resolveCommand(args []string) (cmd command, rest []string, ok bool) {
+	if len(args) == 0 || isHelpArg(args[0]) || args[0] == "help" {
+		return command{}, nil, false
+	}
+	if strings.HasPrefix(args[0], "-") {
+		return commands[0], args, true
+	}
+	for _, cmd := range commands {
+		if cmd.Name == args[0] {
+			return cmd, args[1:], true
+		}
+	}
+	return command{}, nil, false
+}
+
+// isHelpArg reports whether arg asks for help
+func isHelpArg(arg string) bool {
+	return arg == "-h" || arg == "-help" || arg == "--help"
+}
+
+// printUsage writes the top-level usage listing the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: %s <command> [flags]\n\nCommands:\n", os.Args[0])
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, cmd := range commands {
+		fmt.Fprintf(table, "  %s\t%s\n", cmd.Name, cmd.Summary)
+	}
+	table.Flush()
+	fmt.Fprintf(w, "\nRun '%s <command> -h' for the command's flags. Flags without a command,\ne.g. '%s -local -today', run schedule.\n", os.Args[0], os.Args[0])
+}
+
+// --- teams ---
+
+// runTeamsCommand implements "teams", printing the team table
+func runTeamsCommand(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" teams", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s teams [CODE|ID ...]\n\nLists the NHL teams, or only the given ones, with the city codes and IDs\naccepted by -teams, -team-webhook and -team-role.\n", os.Args[0])
+	}
+	parseCommandFlags(flags, args, true)
+
+	teams, err := selectTeams(flags.Args())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	writeTeamTable(os.Stdout, teams)
+}
+
+// selectTeams returns the teams matching the identifiers (city codes or team
+// IDs), or every team when there are none
+func selectTeams(identifiers []string) ([]nhlTeam, error) {
+	if len(identifiers) == 0 {
+		return nhlTeams, nil
+	}
+
+	var teams []nhlTeam
+	for _, identifier := range identifiers {
+		teamID, err := parseTeamIdentifier(identifier)
+		if err != nil {
+			return nil, err
+		}
+		i := slices.IndexFunc(nhlTeams, func(team nhlTeam) bool { return team.ID == teamID })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown team %s", identifier)
+		}
+		teams = append(teams, nhlTeams[i])
+	}
+	return teams, nil
+}
+
+// writeTeamTable prints teams as a code, ID and name table
+func writeTeamTable(w io.Writer, teams []nhlTeam) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "CODE\tID\tNAME")
+	for _, team := range teams {
+		fmt.Fprintf(table, "%s\t%d\t%s\n", team.Code, team.ID, team.Name)
+	}
+	table.Flush()
+}
+
+// --- games ---
+
+// runGamesCommand implements "games", previewing the slate schedule would
+// pick without connecting to a task sink
+func runGamesCommand(args []string) {
+	config := &Config{}
+	var teamsStr, timezone string
+	flags := flag.NewFlagSet(os.Args[0]+" games", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s games [flags]\n\nLists the games schedule would pick for the same -date, -today, -teams and\n-all, and when their tracking tasks would run, without creating any tasks.\n\nFlags:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	addSlateFlags(flags, config, &teamsStr, &timezone)
+	flags.StringVar(&config.LogFormat, "log-format", "text", "Log output format: text or json")
+	flags.StringVar(&config.LogLevel, "log-level", "warn", "Minimum log level: debug, info, warn or error")
+	parseCommandFlags(flags, args, false)
+
+	logger, err := newLogger(os.Stderr, config.LogFormat, config.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	slog.SetDefault(logger)
+	resolveSlate(config, teamsStr, timezone)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fetched, err := fetchGamesForDate(ctx, config.Date)
+	if err != nil {
+		fatal(ExitNHLUnavailable, "Failed to fetch games", "error", err)
+	}
+	games := filterGamesForTeams(fetched, config.Teams)
+	if config.Today {
+		games = filterUpcomingGames(games)
+	}
+	writeGameTable(os.Stdout, games, config.TimeZone)
+}
+
+// gameTypeNames names the NHL API's game types
+var gameTypeNames = map[int]string{
+	1: "preseason",
+	2: "regular",
+	3: "playoffs",
+	4: "all-star",
+}
+
+// writeGameTable prints games with their start and tracking task times in location
+func writeGameTable(w io.Writer, games []Game, location *time.Location) {
+	if len(games) == 0 {
+		fmt.Fprintln(w, "No matching games")
+		return
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "GAME\tMATCHUP\tSTART\tTYPE\tSTATE\tTASK AT")
+	for _, game := range games {
+		start, taskAt := game.StartTime, "-"
+		if startTime, err := time.Parse(time.RFC3339, game.StartTime); err == nil {
+			start = startTime.In(location).Format("2006-01-02 15:04 MST")
+			taskAt = gameTaskScheduleTime(startTime).In(location).Format("2006-01-02 15:04 MST")
+		}
+		gameType, ok := gameTypeNames[game.GameType]
+		if !ok {
+			gameType = strconv.Itoa(game.GameType)
+		}
+		state := game.GameState
+		if state == "" {
+			state = "-"
+		}
+		fmt.Fprintf(table, "%d\t%s @ %s\t%s\t%s\t%s\t%s\n", game.ID, game.AwayTeam.Abbrev, game.HomeTeam.Abbrev, start, gameType, state, taskAt)
+	}
+	table.Flush()
+}
+
+// --- history ---
+
+// runHistoryCommand implements "history", querying the decisions schedule
+// recorded in -history-db
+func runHistoryCommand(args []string) {
+	var dbPath, runID, timezone string
+	var gameID, limit int
+	var since time.Duration
+	var asJSON bool
+	flags := flag.NewFlagSet(os.Args[0]+" history", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s history [flags]\n\nLists the scheduling decisions recorded by schedule, newest first.\n\nFlags:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&dbPath, "history-db", "", "Database schedule recorded its decisions in (can also be set via HISTORY_DB env var)")
+	flags.IntVar(&gameID, "game", 0, "Only show decisions for this NHL game ID")
+	flags.StringVar(&runID, "run", "", "Only show decisions made by this run ID")
+	flags.DurationVar(&since, "since", 0, "Only show decisions made within this long, e.g. 72h (0 for all)")
+	flags.IntVar(&limit, "limit", 50, "Maximum decisions to show (0 for all)")
+	flags.StringVar(&timezone, "tz", "", "IANA time zone to show times in (default: host local time zone)")
+	flags.BoolVar(&asJSON, "json", false, "Print each decision as a JSON line, including errors and payload hashes")
+	parseCommandFlags(flags, args, false)
+
+	location := time.Local
+	if timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(timezone); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid -tz: %v\n", err)
+			os.Exit(ExitConfigError)
+		}
+	}
+	if since < 0 || limit < 0 {
+		fmt.Fprintln(os.Stderr, "Error: -since and -limit must not be negative")
+		os.Exit(ExitConfigError)
+	}
+
+	if dbPath == "" {
+		dbPath = os.Getenv("HISTORY_DB")
+	}
+	if dbPath == "" {
+		fmt.Fprintln(os.Stderr, "Error: -history-db or HISTORY_DB must name the history database")
+		os.Exit(ExitConfigError)
+	}
+
+	query := history.Query{GameID: gameID, RunID: runID, Limit: limit}
+	if since > 0 {
+		query.Since = time.Now().Add(-since)
+	}
+
+	store, err := history.OpenReadOnly(dbPath)
+	if errors.Is(err, os.ErrNotExist) {
+		fmt.Printf("No scheduling history at %s\n", dbPath)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitTotalFailure)
+	}
+	defer store.Close()
+
+	records, err := store.Find(query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitTotalFailure)
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, record := range records {
+			encoder.Encode(record)
+		}
+		return
+	}
+	writeHistoryTable(os.Stdout, records, location)
+}
+
+// writeHistoryTable prints history records with their times in location
+func writeHistoryTable(w io.Writer, records []history.Record, location *time.Location) {
+	if len(records) == 0 {
+		fmt.Fprintln(w, "No matching decisions")
+		return
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "RECORDED\tRUN\tGAME\tSTATUS\tTASK AT\tTASK\tSINK")
+	for _, record := range records {
+		taskAt, task := "-", "-"
+		if !record.ScheduleTime.IsZero() {
+			taskAt = record.ScheduleTime.In(location).Format("2006-01-02 15:04 MST")
+		}
+		if record.TaskName != "" {
+			task = path.Base(record.TaskName)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", record.RecordedAt.In(location).Format("2006-01-02 15:04:05"),
+			record.RunID, record.GameID, record.Status, taskAt, task, record.Sink)
+	}
+	table.Flush()
+}
+
+// --- notify ---
+
+// runNotifyCommand implements "notify test", sending a message through every
+// configured Discord webhook
+func runNotifyCommand(args []string) {
+	_, args = subcommand("notify", args, "test")
+
+	config := &Config{TeamWebhooks: make(map[string]string)}
+	var message string
+	flags := flag.NewFlagSet(os.Args[0]+" notify test", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s notify test [flags]\n\nSends a test message to the -discord-webhook and each -team-webhook, so\nwebhooks can be checked without scheduling a run.\n\nFlags:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&config.DiscordWebhookURL, "discord-webhook", "", "Discord webhook URL for notifications (can also be set via DISCORD_WEBHOOK_URL env var)")
+	flags.Var(teamMapFlag(config.TeamWebhooks), "team-webhook", "Per-team Discord webhook as CODE=URL; repeatable (can also be set via DISCORD_TEAM_WEBHOOKS env var)")
+	flags.StringVar(&message, "message", "Test notification from gameTaskEmulator", "Text of the test message")
+	parseCommandFlags(flags, args, false)
+
+	if config.DiscordWebhookURL == "" {
+		config.DiscordWebhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
+	}
+	fromEnv := teamMapFlag{}
+	if err := fromEnv.Set(os.Getenv("DISCORD_TEAM_WEBHOOKS")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid DISCORD_TEAM_WEBHOOKS: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	for code, webhookURL := range fromEnv {
+		if _, set := config.TeamWebhooks[code]; !set {
+			config.TeamWebhooks[code] = webhookURL
+		}
+	}
+
+	targets := notifyTargets(config)
+	if len(targets) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: no Discord webhook configured; set -discord-webhook, -team-webhook, DISCORD_WEBHOOK_URL or DISCORD_TEAM_WEBHOOKS")
+		os.Exit(ExitConfigError)
+	}
+
+	if failed := sendTestNotifications(os.Stdout, targets, message); failed > 0 {
+		os.Exit(gameFailureExitCode(failOnAny, len(targets), failed))
+	}
+}
+
+// notifyTarget is a webhook the notify test command sends to
+type notifyTarget struct {
+	Label  string
+	Sender notification.Sender
+}
+
+// notifyTargets returns the default webhook followed by the team webhooks in
+// city code order
+func notifyTargets(config *Config) []notifyTarget {
+	var targets []notifyTarget
+	if config.DiscordWebhookURL != "" {
+		targets = append(targets, notifyTarget{"default", notification.NewDiscordSenderWithOptions(config.DiscordWebhookURL, notification.DiscordOptions{HTTPClient: discordClient})})
+	}
+
+	codes := make([]string, 0, len(config.TeamWebhooks))
+	for code := range config.TeamWebhooks {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		sender := notification.NewDiscordSenderWithOptions(config.TeamWebhooks[code], notification.DiscordOptions{HTTPClient: discordClient})
+		targets = append(targets, notifyTarget{code, sender})
+	}
+	return targets
+}
+
+// sendTestNotifications sends message to every target, reporting each
+// outcome to w, and returns how many sends failed
+func sendTestNotifications(w io.Writer, targets []notifyTarget, message string) int {
+	failed := 0
+	for _, target := range targets {
+		text := message
+		if target.Label != "default" {
+			text = fmt.Sprintf("%s (%s)", message, target.Label)
+		}
+		if err := target.Sender.Send(text); err != nil {
+			fmt.Fprintf(w, "%s: failed: %v\n", target.Label, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "%s: sent\n", target.Label)
+	}
+	return failed
+}
+
+// --- version ---
+
+// runVersionCommand implements "version"
+func runVersionCommand(args []string) {
+	flags := flag.NewFlagSet(os.Args[0]+" version", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s version\n\nPrints the release version, the commit it was built from and the Go version.\n", os.Args[0])
+	}
+	parseCommandFlags(flags, args, false)
+
+	info, _ := debug.ReadBuildInfo()
+	fmt.Println(versionString(info))
+}
+
+// versionString describes the build: the release version, then the VCS
+// revision and time Go stamped into the binary, if any
+func versionString(info *debug.BuildInfo) string {
+	var revision, built string
+	modified := false
+	if info != nil {
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				revision = setting.Value
+			case "vcs.time":
+				built = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+	}
+
+	details := []string{}
+	if revision != "" {
+		if len(revision) > 12 {
+			revision = revision[:12]
+		}
+		if modified {
+			revision += "-dirty"
+		}
+		details = append(details, "commit "+revision)
+	}
+	if built != "" {
+		details = append(details, "built "+built)
+	}
+	details = append(details, runtime.Version())
+	return fmt.Sprintf("gameTaskEmulator %s (%s)", version, strings.Join(details, ", "))
+}
+
+// parseCommandFlags parses a simple subcommand's flags, exiting with
+// ExitConfigError on invalid flags or, unless allowArgs, stray arguments
+func parseCommandFlags(flags *flag.FlagSet, args []string, allowArgs bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(ExitOK)
+		}
+		os.Exit(ExitConfigError)
+	}
+	if !allowArgs && flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments: %v\n", flags.Args())
+		flags.Usage()
+		os.Exit(ExitConfigError)
+	}
+}
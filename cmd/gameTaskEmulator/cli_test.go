@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/history"
+)
+
+func TestResolveCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantCmd  string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{"named command", []string{"teams", "DAL"}, "teams", []string{"DAL"}, true},
+		{"flags alias schedule", []string{"-local", "-today"}, "schedule", []string{"-local", "-today"}, true},
+		{"explicit schedule", []string{"schedule", "-local"}, "schedule", []string{"-local"}, true},
+		{"no arguments", nil, "", nil, false},
+		{"help flag", []string{"-h"}, "", nil, false},
+		{"help command", []string{"help"}, "", nil, false},
+		{"unknown command", []string{"bogus"}, "", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, args, ok := resolveCommand(tt.args)
+			if ok != tt.wantOK || cmd.Name != tt.wantCmd {
+				t.Fatalf("resolveCommand(%v) = %q, %v, want %q, %v", tt.args, cmd.Name, ok, tt.wantCmd, tt.wantOK)
+			}
+			if strings.Join(args, " ") != strings.Join(tt.wantArgs, " ") {
+				t.Errorf("resolveCommand(%v) args = %v, want %v", tt.args, args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestParseScheduleFlags(t *testing.T) {
+	config := parseScheduleFlags([]string{"-local", "-today", "-teams", "CHI,DAL", "-tz", "America/Chicago"})
+
+	if !config.LocalMode || !config.Today {
+		t.Errorf("LocalMode, Today = %v, %v, want true, true", config.LocalMode, config.Today)
+	}
+	if len(config.Teams) != 2 || config.Teams[0] != 16 || config.Teams[1] != 25 {
+		t.Errorf("Teams = %v, want [16 25]", config.Teams)
+	}
+	if config.TimeZone.String() != "America/Chicago" || config.Date == "" {
+		t.Errorf("TimeZone, Date = %s, %q, want America/Chicago and today's date", config.TimeZone, config.Date)
+	}
+}
+
+// --- teams ---
+
+func TestTeamTableCoversCityCodes(t *testing.T) {
+	if len(nhlTeams) != len(cityCodeToTeamID) {
+		t.Fatalf("nhlTeams has %d teams but %d distinct codes", len(nhlTeams), len(cityCodeToTeamID))
+	}
+
+	var out bytes.Buffer
+	writeTeamTable(&out, nhlTeams)
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != len(nhlTeams)+1 || !strings.HasPrefix(lines[0], "CODE") {
+		t.Fatalf("team table has %d lines, want a header and %d teams:\n%s", len(lines), len(nhlTeams), out.String())
+	}
+}
+
+func TestSelectTeams(t *testing.T) {
+	teams, err := selectTeams([]string{"dal", "16"})
+	if err != nil {
+		t.Fatalf("selectTeams() returned error: %v", err)
+	}
+	if len(teams) != 2 || teams[0].Name != "Dallas Stars" || teams[1].Code != "CHI" {
+		t.Errorf("selectTeams() = %+v, want Dallas then Chicago", teams)
+	}
+
+	if _, err := selectTeams([]string{"99"}); err == nil {
+		t.Error("selectTeams() accepted an unknown team ID")
+	}
+	if _, err := selectTeams([]string{"XYZ"}); err == nil {
+		t.Error("selectTeams() accepted an unknown city code")
+	}
+}
+
+// --- games ---
+
+func TestWriteGameTable(t *testing.T) {
+	games := newTestGames(2)
+	games[0].StartTime = "2025-01-15T19:00:00Z"
+	games[0].GameType = 2
+	games[0].GameState = "FUT"
+	games[1].StartTime = "not a time"
+	games[1].GameType = 19
+
+	chicago, _ := time.LoadLocation("America/Chicago")
+	var out bytes.Buffer
+	writeGameTable(&out, games, chicago)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "GAME") {
+		t.Fatalf("game table = %q, want a header and 2 games", out.String())
+	}
+	for _, want := range []string{"2024020001", "DAL @ BOS", "2025-01-15 13:00 CST", "regular", "FUT", "2025-01-15 12:55 CST"} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q is missing %q", lines[1], want)
+		}
+	}
+	if fields := strings.Fields(lines[2]); fields[len(fields)-1] != "-" || !strings.Contains(lines[2], " 19 ") {
+		t.Errorf("row %q, want the raw type and no task time for an unparsable start", lines[2])
+	}
+
+	out.Reset()
+	writeGameTable(&out, nil, chicago)
+	if out.String() != "No matching games\n" {
+		t.Errorf("empty game table = %q", out.String())
+	}
+}
+
+// --- history ---
+
+func TestWriteHistoryTable(t *testing.T) {
+	recorded := time.Date(2025, 1, 15, 18, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+	writeHistoryTable(&out, []history.Record{
+		{GameID: 2024020712, RunID: "run-2", Status: history.StatusUnchanged, TaskName: "projects/p/locations/l/queues/q/tasks/123",
+			ScheduleTime: recorded.Add(time.Hour), Sink: "cloudtasks:q", RecordedAt: recorded},
+		{GameID: 2024020713, RunID: "run-2", Status: history.StatusFailed, Sink: "cloudtasks:q", RecordedAt: recorded},
+	}, time.UTC)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "RECORDED") {
+		t.Fatalf("history table = %q, want a header and 2 records", out.String())
+	}
+	for _, want := range []string{"2025-01-15 18:00:00", "run-2", "2024020712", "unchanged", "2025-01-15 19:00 UTC", " 123 "} {
+		if !strings.Contains(lines[1], want) {
+			t.Errorf("row %q is missing %q", lines[1], want)
+		}
+	}
+	if fields := strings.Fields(lines[2]); len(fields) != 8 || fields[5] != "-" || fields[6] != "-" {
+		t.Errorf("row %q, want no task time or name for a failed decision", lines[2])
+	}
+}
+
+// --- notify ---
+
+func TestSendTestNotifications(t *testing.T) {
+	var received []string
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ok.Close()
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer broken.Close()
+
+	targets := notifyTargets(&Config{
+		DiscordWebhookURL: ok.URL,
+		TeamWebhooks:      map[string]string{"DAL": ok.URL, "CHI": broken.URL},
+	})
+	if len(targets) != 3 || targets[0].Label != "default" || targets[1].Label != "CHI" || targets[2].Label != "DAL" {
+		t.Fatalf("notifyTargets() labels = %+v, want default, CHI, DAL", targets)
+	}
+
+	var out bytes.Buffer
+	if failed := sendTestNotifications(&out, targets, "hello"); failed != 1 {
+		t.Errorf("sendTestNotifications() failed = %d, want 1\n%s", failed, out.String())
+	}
+	if len(received) != 2 || !strings.Contains(received[0], `"hello"`) || !strings.Contains(received[1], "hello (DAL)") {
+		t.Errorf("webhook received %v, want the default and DAL messages", received)
+	}
+	if !strings.Contains(out.String(), "CHI: failed") || !strings.Contains(out.String(), "DAL: sent") {
+		t.Errorf("report = %q, want per-webhook outcomes", out.String())
+	}
+}
+
+func TestNotifyTargetsSkipsUnconfiguredWebhooks(t *testing.T) {
+	if targets := notifyTargets(&Config{}); len(targets) != 0 {
+		t.Errorf("notifyTargets() = %+v, want none", targets)
+	}
+}
+
+// --- version ---
+
+func TestVersionString(t *testing.T) {
+	info := &debug.BuildInfo{Settings: []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+		{Key: "vcs.time", Value: "2025-01-15T19:00:00Z"},
+		{Key: "vcs.modified", Value: "true"},
+	}}
+
+	want := "gameTaskEmulator dev (commit 0123456789ab-dirty, built 2025-01-15T19:00:00Z, " + runtime.Version() + ")"
+	if got := versionString(info); got != want {
+		t.Errorf("versionString() = %q, want %q", got, want)
+	}
+	if got, want := versionString(nil), "gameTaskEmulator dev ("+runtime.Version()+")"; got != want {
+		t.Errorf("versionString(nil) = %q, want %q", got, want)
+	}
+}
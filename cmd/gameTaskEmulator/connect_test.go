@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// healthServer answers ListQueues with err, or with an empty list when err is nil.
+type healthServer struct {
+	taskspb.UnimplementedCloudTasksServer
+	err error
+}
+
+func (s *healthServer) ListQueues(ctx context.Context, req *taskspb.ListQueuesRequest) (*taskspb.ListQueuesResponse, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &taskspb.ListQueuesResponse{}, nil
+}
+
+// startHealthServer serves a plaintext Cloud Tasks endpoint and returns its address.
+func startHealthServer(t *testing.T, err error) string {
+	t.Helper()
+
+	listener, listenErr := net.Listen("tcp", "127.0.0.1:0")
+	if listenErr != nil {
+		t.Fatalf("failed to listen: %v", listenErr)
+	}
+	server := grpc.NewServer()
+	taskspb.RegisterCloudTasksServer(server, &healthServer{err: err})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func newConnectConfig(endpoint string) *Config {
+	config := newTestConfig()
+	config.EmulatorHost = endpoint
+	config.ProjectID, config.Location = "localproject", "us-south1"
+	config.DialTimeout = 500 * time.Millisecond
+	config.RPCAttempts = 1
+	return config
+}
+
+func TestTransportCredentials(t *testing.T) {
+	dir := t.TempDir()
+
+	server := httptest.NewTLSServer(nil)
+	server.Close()
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	emptyFile := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(emptyFile, []byte("not a certificate\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		tls       bool
+		caFile    string
+		wantProto string
+		wantErr   string
+	}{
+		{"emulator default is insecure", false, "", "insecure", ""},
+		{"CA file ignored without TLS", false, filepath.Join(dir, "missing.pem"), "insecure", ""},
+		{"TLS with system roots", true, "", "tls", ""},
+		{"TLS with CA file", true, caFile, "tls", ""},
+		{"missing CA file", true, filepath.Join(dir, "missing.pem"), "", "failed to read TLS CA file"},
+		{"CA file without certificates", true, emptyFile, "", "no certificates found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.TLS = tt.tls
+			config.TLSCAFile = tt.caFile
+
+			creds, err := transportCredentials(config)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("transportCredentials() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("transportCredentials() returned error: %v", err)
+			}
+			if got := creds.Info().SecurityProtocol; got != tt.wantProto {
+				t.Errorf("security protocol = %q, want %q", got, tt.wantProto)
+			}
+		})
+	}
+}
+
+func TestConnectToTasksServiceUsesSelectedCredentials(t *testing.T) {
+	endpoint := startHealthServer(t, nil)
+
+	t.Run("insecure reaches a plaintext emulator", func(t *testing.T) {
+		_, conn, err := connectToTasksService(context.Background(), newConnectConfig(endpoint))
+		if err != nil {
+			t.Fatalf("connectToTasksService() returned error: %v", err)
+		}
+		conn.Close()
+	})
+
+	t.Run("TLS fails against a plaintext emulator", func(t *testing.T) {
+		config := newConnectConfig(endpoint)
+		config.TLS = true
+
+		_, _, err := connectToTasksService(context.Background(), config)
+		if err == nil || !strings.Contains(err.Error(), "failed to connect") {
+			t.Fatalf("connectToTasksService() error = %v, want a connection failure", err)
+		}
+	})
+}
+
+func TestCheckTasksServiceFailure(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantCode codes.Code
+	}{
+		{"healthy", nil, codes.OK},
+		{"permission denied", status.Error(codes.PermissionDenied, "caller lacks cloudtasks.queues.list"), codes.PermissionDenied},
+		{"unavailable", status.Error(codes.Unavailable, "emulator shutting down"), codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newConnectConfig(startHealthServer(t, tt.err))
+
+			client, conn, err := connectToTasksService(context.Background(), config)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatalf("connectToTasksService() returned error: %v", err)
+				}
+				defer conn.Close()
+				if err := checkTasksService(context.Background(), client, config); err != nil {
+					t.Errorf("checkTasksService() returned error: %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				conn.Close()
+				t.Fatal("connectToTasksService() succeeded, want the health check to fail")
+			}
+			if !strings.Contains(err.Error(), "health check failed (ListQueues projects/localproject/locations/us-south1)") {
+				t.Errorf("error = %q, want it to name the failed health check", err)
+			}
+			var statusErr interface{ GRPCStatus() *status.Status }
+			if !errors.As(err, &statusErr) || statusErr.GRPCStatus().Code() != tt.wantCode {
+				t.Errorf("error = %v, want it to wrap a %s status", err, tt.wantCode)
+			}
+		})
+	}
+}
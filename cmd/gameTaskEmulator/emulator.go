@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/emulator"
+	"google.golang.org/grpc"
+)
+
+// EmulatorConfig holds the emulator subcommand's settings
+type EmulatorConfig struct {
+	Addr        string        // gRPC listen address for the Cloud Tasks API
+	InspectAddr string        // HTTP listen address for the inspection endpoint; empty disables it
+	StatePath   string        // File queues and tasks are persisted to; empty keeps them in memory
+	Tick        time.Duration // How often due tasks are dispatched
+	LogFormat   string        // Log output format: text or json
+	LogLevel    string        // Minimum log level
+}
+
+// parseEmulatorFlags parses and validates the emulator subcommand's flags
+func parseEmulatorFlags(args []string) *EmulatorConfig {
+	config := &EmulatorConfig{}
+
+	flags := flag.NewFlagSet(os.Args[0]+" emulator", flag.ContinueOnError)
+	flags.StringVar(&config.Addr, "addr", "localhost:8123", "Address to serve the Cloud Tasks gRPC API on")
+	flags.StringVar(&config.InspectAddr, "inspect-addr", "localhost:8124", "Address to serve the JSON inspection endpoint on (empty disables it)")
+	flags.StringVar(&config.StatePath, "state", "", "File to persist queues and tasks to (default: in memory only)")
+	flags.DurationVar(&config.Tick, "tick", 100*time.Millisecond, "How often due tasks are dispatched")
+	flags.StringVar(&config.LogFormat, "log-format", "text", "Log output format: text or json")
+	flags.StringVar(&config.LogLevel, "log-level", "info", "Minimum log level: debug, info, warn or error")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(ExitOK)
+		}
+		os.Exit(ExitConfigError)
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments: %v\n", flags.Args())
+		os.Exit(ExitConfigError)
+	}
+
+	logger, err := newLogger(os.Stderr, config.LogFormat, config.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	slog.SetDefault(logger)
+
+	if config.Tick <= 0 {
+		fatal(ExitConfigError, "-tick must be positive", "tick", config.Tick)
+	}
+	return config
+}
+
+// runEmulator serves the Cloud Tasks emulator until interrupted
+func runEmulator(args []string) {
+	config := parseEmulatorFlags(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server, err := emulator.New(emulator.Options{StatePath: config.StatePath, Tick: config.Tick})
+	if err != nil {
+		fatal(ExitConfigError, "Failed to load emulator state", "error", err)
+	}
+
+	listener, err := net.Listen("tcp", config.Addr)
+	if err != nil {
+		fatal(ExitConfigError, "Failed to listen for the Cloud Tasks API", "addr", config.Addr, "error", err)
+	}
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+
+	var inspectServer *http.Server
+	if config.InspectAddr != "" {
+		inspectServer = &http.Server{Addr: config.InspectAddr, Handler: server.InspectHandler()}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		server.Run(ctx)
+	}()
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			slog.Error("Cloud Tasks API server failed", "error", err)
+			stop()
+		}
+	}()
+	if inspectServer != nil {
+		go func() {
+			if err := inspectServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Inspection server failed", "error", err)
+				stop()
+			}
+		}()
+	}
+
+	slog.Info("Cloud Tasks emulator started", "addr", listener.Addr().String(), "inspect_addr", config.InspectAddr,
+		"state", config.StatePath, "contents", server.String())
+
+	<-ctx.Done()
+	slog.Info("Stopping Cloud Tasks emulator")
+
+	grpcServer.GracefulStop()
+	if inspectServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		inspectServer.Shutdown(shutdownCtx)
+		cancel()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Process exit codes, so cron, systemd and run-task.sh can tell a clean run
+// from one that needs attention or a retry
+const (
+	ExitOK             = 0 // Every game was scheduled, or there was nothing to schedule
+	ExitPartialFailure = 2 // Some games failed to schedule
+	ExitTotalFailure   = 3 // No game could be scheduled
+	ExitConfigError    = 4 // Invalid flags or environment; retrying will not help
+	ExitNHLUnavailable = 5 // The NHL API could not be reached or returned an error
+)
+
+// failOnPolicy decides which game failures make the run exit non-zero
+type failOnPolicy string
+
+const (
+	failOnAny   failOnPolicy = "any"   // Exit non-zero if any game failed (default)
+	failOnAll   failOnPolicy = "all"   // Exit non-zero only if every game failed
+	failOnNever failOnPolicy = "never" // Game failures never change the exit code
+)
+
+// parseFailOn parses a -fail-on value
+func parseFailOn(s string) (failOnPolicy, error) {
+	switch policy := failOnPolicy(strings.ToLower(strings.TrimSpace(s))); policy {
+	case failOnAny, failOnAll, failOnNever:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown policy %q (use any, all or never)", s)
+	}
+}
+
+// exitError is a run-aborting error that ends the process with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+// withExitCode marks err as ending the run with code
+func withExitCode(code int, err error) error {
+	return &exitError{code: code, err: err}
+}
+
+// runExitCode returns the exit code for a run. A non-nil err aborted the run
+// and decides the code on its own, ExitTotalFailure unless it carries one;
+// otherwise the game failures decide it under policy
+func runExitCode(policy failOnPolicy, err error, processed, failed int) int {
+	var exitErr *exitError
+	switch {
+	case err == nil:
+		return gameFailureExitCode(policy, processed, failed)
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	default:
+		return ExitTotalFailure
+	}
+}
+
+// gameFailureExitCode returns the exit code for a run that processed the given
+// number of games, of which failed could not be scheduled
+func gameFailureExitCode(policy failOnPolicy, processed, failed int) int {
+	switch {
+	case failed == 0 || policy == failOnNever:
+		return ExitOK
+	case failed == processed:
+		return ExitTotalFailure
+	case policy == failOnAny:
+		return ExitPartialFailure
+	default:
+		return ExitOK
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestParseFailOn(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    failOnPolicy
+		wantErr bool
+	}{
+		{"any", failOnAny, false},
+		{" ALL ", failOnAll, false},
+		{"never", failOnNever, false},
+		{"some", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseFailOn(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("parseFailOn(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRunExitCode(t *testing.T) {
+	nhlDown := withExitCode(ExitNHLUnavailable, errors.New("Failed to fetch games: 503"))
+	badConfig := withExitCode(ExitConfigError, errors.New("-rpc-attempts must be at least 1"))
+	sinkDown := errors.New("Failed to connect to tasks service: connection refused")
+
+	// Outcomes of a run that processed 4 games
+	outcomes := []struct {
+		name   string
+		err    error
+		failed int
+	}{
+		{"ok", nil, 0},
+		{"partial", nil, 1},
+		{"total", nil, 4},
+		{"config", badConfig, 0},
+		{"NHL API", nhlDown, 0},
+		{"NHL API wrapped", fmt.Errorf("run aborted: %w", nhlDown), 0},
+		{"aborted", sinkDown, 0},
+	}
+
+	// Expected exit code of each outcome, in order, under each -fail-on policy
+	tests := []struct {
+		policy failOnPolicy
+		want   []int
+	}{
+		{failOnAny, []int{ExitOK, ExitPartialFailure, ExitTotalFailure, ExitConfigError, ExitNHLUnavailable, ExitNHLUnavailable, ExitTotalFailure}},
+		{failOnAll, []int{ExitOK, ExitOK, ExitTotalFailure, ExitConfigError, ExitNHLUnavailable, ExitNHLUnavailable, ExitTotalFailure}},
+		{failOnNever, []int{ExitOK, ExitOK, ExitOK, ExitConfigError, ExitNHLUnavailable, ExitNHLUnavailable, ExitTotalFailure}},
+	}
+
+	for _, tt := range tests {
+		for i, outcome := range outcomes {
+			t.Run(string(tt.policy)+"/"+outcome.name, func(t *testing.T) {
+				if got := runExitCode(tt.policy, outcome.err, 4, outcome.failed); got != tt.want[i] {
+					t.Errorf("runExitCode(%s, %v, 4, %d) = %d, want %d", tt.policy, outcome.err, outcome.failed, got, tt.want[i])
+				}
+			})
+		}
+	}
+}
+
+func TestGameFailureExitCodeIgnoresUnattemptedGames(t *testing.T) {
+	// With nothing attempted, for example when every game was unchanged, the run is clean
+	for _, policy := range []failOnPolicy{failOnAny, failOnAll, failOnNever} {
+		if got := gameFailureExitCode(policy, 0, 0); got != ExitOK {
+			t.Errorf("gameFailureExitCode(%s, 0, 0) = %d, want %d", policy, got, ExitOK)
+		}
+	}
+}
+
+func TestExitErrorKeepsMessage(t *testing.T) {
+	cause := errors.New("Failed to fetch games: 503")
+	err := withExitCode(ExitNHLUnavailable, cause)
+	if err.Error() != cause.Error() || !errors.Is(err, cause) {
+		t.Errorf("withExitCode() = %q, want it to read and unwrap as %q", err, cause)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/sink"
+)
+
+func TestNewLogger(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		level   string
+		wantErr bool
+	}{
+		{"text", "text", "info", false},
+		{"json", "json", "debug", false},
+		{"format is case-insensitive", "JSON", "warn", false},
+		{"unknown format", "xml", "info", true},
+		{"unknown level", "text", "verbose", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger, err := newLogger(&bytes.Buffer{}, tt.format, tt.level)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newLogger(%q, %q) error = %v, want error %v", tt.format, tt.level, err, tt.wantErr)
+			}
+			if !tt.wantErr && logger == nil {
+				t.Fatalf("newLogger(%q, %q) returned a nil logger", tt.format, tt.level)
+			}
+		})
+	}
+}
+
+func TestNewLoggerCarriesRunID(t *testing.T) {
+	var out bytes.Buffer
+	logger, err := newLogger(&out, "json", "info")
+	if err != nil {
+		t.Fatalf("newLogger() returned error: %v", err)
+	}
+
+	// Run commands attach the run ID once, as runSchedule does with slog.SetDefault
+	logger = logger.With("run_id", "abc123")
+	logger.Debug("filtered out by level")
+	logger.Info("Processing games", "count", 2)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logger wrote %d lines, want only the info record:\n%s", len(lines), out.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if record["run_id"] != "abc123" || record["msg"] != "Processing games" {
+		t.Errorf("log record = %v, want the message with run_id", record)
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	first, second := newRunID(), newRunID()
+	if !regexp.MustCompile(`^[0-9a-f]{16}$`).MatchString(first) {
+		t.Errorf("newRunID() = %q, want 16 hex digits", first)
+	}
+	if first == second {
+		t.Errorf("newRunID() returned %q twice", first)
+	}
+}
+
+func TestCreatedTasksCarryRunID(t *testing.T) {
+	config := newTestConfig()
+	config.RunID = "run-42"
+	config.ReminderURL = "http://relay.example.com/remind"
+	config.ReminderLead = 30 * time.Minute
+	game := newTestGames(1)[0]
+
+	tests := []struct {
+		name   string
+		create func(context.Context, sink.TaskSink) (sink.Task, error)
+	}{
+		{"game task", func(ctx context.Context, s sink.TaskSink) (sink.Task, error) {
+			return createGameTask(ctx, s, config, game)
+		}},
+		{"reminder task", func(ctx context.Context, s sink.TaskSink) (sink.Task, error) {
+			return createReminderTask(ctx, s, config, game)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := sink.NewMemory()
+			if _, err := tt.create(context.Background(), memory); err != nil {
+				t.Fatalf("create returned error: %v", err)
+			}
+
+			tasks, _ := memory.List(context.Background())
+			if len(tasks) != 1 {
+				t.Fatalf("sink holds %d tasks, want 1", len(tasks))
+			}
+			if got := tasks[0].Headers[RunIDHeader]; got != "run-42" {
+				t.Errorf("%s header = %q, want the run ID", RunIDHeader, got)
+			}
+			if got := tasks[0].Attributes["runId"]; got != "run-42" {
+				t.Errorf("runId attribute = %q, want the run ID", got)
+			}
+		})
+	}
+}
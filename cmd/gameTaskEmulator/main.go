@@ -5,20 +5,43 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 	"time"
+	_ "time/tzdata" // Embed zone data so -tz works in minimal containers
 
+	"cloud.google.com/go/pubsub"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/grpcretry"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/history"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/metrics"
 	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/notification"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/sink"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/time/rate"
+	"google.golang.org/api/option"
 	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
 )
 
 const (
@@ -30,30 +53,79 @@ const (
 	NHLAPIBaseURL = "https://api-web.nhle.com/v1"
 )
 
+// runMetrics records the metrics of the current run
+var runMetrics = metrics.New()
+
+// runTracing exports the run's spans; it is replaced in main once flags are parsed
+var runTracing = &tracing.Provider{}
+
+// nhlClient and discordClient are the traced HTTP clients for the NHL API and Discord webhooks
+var (
+	nhlClient     = tracing.HTTPClient(&http.Client{Timeout: 30 * time.Second})
+	discordClient = tracing.HTTPClient(&http.Client{Timeout: 10 * time.Second})
+)
+
 // Config holds the configuration for the application
 type Config struct {
-	Date              string // Date to query games for (YYYY-MM-DD format)
-	Teams             []int  // Team IDs to filter games for
-	TestMode          bool   // Whether to run in test mode
-	AllTeams          bool   // Whether to include all teams
-	Today             bool   // Whether to filter for today's upcoming games only
-	Production        bool   // Whether to use production task queue
-	Shootout          bool   // Whether to use shootout game ID (2024030412)
-	ProjectID         string // GCP Project ID
-	Location          string // GCP Location
-	QueueName         string // Task Queue name
-	LocalMode         bool   // Whether to send requests to local host
-	HostURL           string // Custom host URL for sending requests
-	DiscordWebhookURL string // Discord webhook URL for notifications
-	EmulatorHost      string // Cloud Tasks emulator host (default: localhost:8123)
+	Date              string                   // Date to query games for (YYYY-MM-DD format)
+	Teams             []int                    // Team IDs to filter games for
+	TestMode          bool                     // Whether to run in test mode
+	AllTeams          bool                     // Whether to include all teams
+	Today             bool                     // Whether to filter for today's upcoming games only
+	Production        bool                     // Whether to use production task queue
+	Shootout          bool                     // Whether to use shootout game ID (2024030412)
+	ProjectID         string                   // GCP Project ID
+	Location          string                   // GCP Location
+	QueueName         string                   // Task Queue name
+	QueueConfig       sink.QueueConfig         // Queue settings applied when the queue is created or updated
+	LocalMode         bool                     // Whether to send requests to local host
+	HostURL           string                   // Custom host URL for sending requests
+	DiscordWebhookURL string                   // Discord webhook URL for notifications
+	DiscordEvents     []notification.EventKind // Notification events the Discord webhook subscribes to
+	TeamWebhooks      map[string]string        // Per-team Discord webhook URLs, keyed by city code
+	TeamRoles         map[string]string        // Per-team Discord role IDs to mention, keyed by city code
+	EmulatorHost      string                   // Cloud Tasks emulator host (default: localhost:8123)
+	ReminderURL       string                   // Notification relay URL for pre-game reminders (empty disables reminders)
+	ReminderLead      time.Duration            // How long before puck drop reminders fire
+	Lang              string                   // Language for notification text (e.g. en, fr)
+	Messages          *notification.Messages   // Message catalog for Lang
+	TimeZone          *time.Location           // Time zone used for "today" and displayed times
+	MetricsAddr       string                   // Address to serve Prometheus metrics on during the run
+	MetricsTextfile   string                   // node_exporter textfile-collector file to write metrics to
+	LogFormat         string                   // Log output format (text or json)
+	LogLevel          string                   // Minimum log level (debug, info, warn, error)
+	OTLPEndpoint      string                   // OTLP gRPC collector address for traces (empty disables tracing)
+	OTLPInsecure      bool                     // Connect to the OTLP collector without TLS
+	FailOn            failOnPolicy             // Which game failures make the run exit non-zero
+	Concurrency       int                      // Number of games processed in parallel
+	RateLimit         float64                  // Maximum task sink requests per second across all workers (0 is unlimited)
+	RPCAttempts       int                      // Attempts per Cloud Tasks RPC on transient errors
+	DialTimeout       time.Duration            // How long to wait for the Cloud Tasks connection and health check
+	TLS               bool                     // Connect to Cloud Tasks over TLS
+	TLSCAFile         string                   // PEM CA bundle used to verify the server (empty uses system roots)
+	Sink              string                   // Task sink that delivers tasks (cloudtasks, memory, local, pubsub or redis)
+	LocalStatePath    string                   // File the local sink persists pending tasks to
+	PubSubTopic       string                   // Pub/Sub topic the pubsub sink publishes to
+	PubSubEmulator    string                   // Pub/Sub emulator host used outside production
+	RedisAddr         string                   // Redis address the redis sink enqueues jobs in
+	RedisPassword     string                   // Redis password (from REDIS_PASSWORD)
+	RedisDB           int                      // Redis database number
+	RedisQueue        string                   // asynq queue the redis sink enqueues jobs on
+	HistoryPath       string                   // bbolt database scheduling decisions are recorded in (empty disables history)
+	Force             bool                     // Schedule games even if history shows them unchanged
+	History           *history.Store           // Open history database; nil when history is disabled or for dry runs
+	RunID             string                   // Correlation ID for this run, attached to logs and tasks
 }
 
 // Game represents a single NHL game with relevant information
 type Game struct {
-	ID        int    `json:"id"`
-	GameDate  string `json:"gameDate"`
-	StartTime string `json:"startTimeUTC"`
-	AwayTeam  struct {
+	ID            int    `json:"id"`
+	GameDate      string `json:"gameDate"`
+	StartTime     string `json:"startTimeUTC"`
+	VenueTimezone string `json:"venueTimezone"` // IANA zone of the arena, e.g. America/New_York
+	GameType      int    `json:"gameType"`      // 1 preseason, 2 regular season, 3 playoffs, 4 all-star
+	GameState     string `json:"gameState"`     // e.g. FUT, PRE, LIVE, FINAL or OFF
+	AwayTeam      struct {
 		ID                       int               `json:"id"`
 		CommonName               map[string]string `json:"commonName"`
 		PlaceName                map[string]string `json:"placeName"`
@@ -102,40 +174,71 @@ type TaskPayload struct {
 	ShouldNotify bool     `json:"ShouldNotify"`
 }
 
+// ReminderPayloadType identifies reminder payloads to the notification relay
+const ReminderPayloadType = "pregame_reminder"
+
+// ReminderPayload represents the payload of a pre-game reminder task sent to the notification relay
+type ReminderPayload struct {
+	Type         string   `json:"type"`
+	Game         GameInfo `json:"game"`
+	LeadMinutes  int      `json:"leadMinutes"`
+	Message      string   `json:"message"`
+	ShouldNotify bool     `json:"ShouldNotify"`
+}
+
+// nhlTeam is an NHL team's city code, team ID and name
+type nhlTeam struct {
+	Code string
+	ID   int
+	Name string
+}
+
+// nhlTeams lists the NHL teams by city code
+var nhlTeams = []nhlTeam{
+	{"ANA", 24, "Anaheim Ducks"},
+	{"ARI", 53, "Arizona Coyotes"},
+	{"BOS", 1, "Boston Bruins"},
+	{"BUF", 7, "Buffalo Sabres"},
+	{"CAR", 12, "Carolina Hurricanes"},
+	{"CBJ", 29, "Columbus Blue Jackets"},
+	{"CGY", 20, "Calgary Flames"},
+	{"CHI", 16, "Chicago Blackhawks"},
+	{"COL", 21, "Colorado Avalanche"},
+	{"DAL", 25, "Dallas Stars"},
+	{"DET", 17, "Detroit Red Wings"},
+	{"EDM", 22, "Edmonton Oilers"},
+	{"FLA", 13, "Florida Panthers"},
+	{"LAK", 26, "Los Angeles Kings"},
+	{"MIN", 30, "Minnesota Wild"},
+	{"MTL", 8, "Montreal Canadiens"},
+	{"NJD", 6, "New Jersey Devils"},
+	{"NSH", 18, "Nashville Predators"},
+	{"NYI", 2, "New York Islanders"},
+	{"NYR", 3, "New York Rangers"},
+	{"OTT", 9, "Ottawa Senators"},
+	{"PHI", 4, "Philadelphia Flyers"},
+	{"PIT", 5, "Pittsburgh Penguins"},
+	{"SEA", 55, "Seattle Kraken"},
+	{"SJS", 28, "San Jose Sharks"},
+	{"STL", 19, "St. Louis Blues"},
+	{"TBL", 14, "Tampa Bay Lightning"},
+	{"TOR", 10, "Toronto Maple Leafs"},
+	{"VAN", 23, "Vancouver Canucks"},
+	{"VGK", 54, "Vegas Golden Knights"},
+	{"WPG", 52, "Winnipeg Jets"},
+	{"WSH", 15, "Washington Capitals"},
+}
+
 // cityCodeToTeamID maps NHL team city codes to their corresponding team IDs
-var cityCodeToTeamID = map[string]int{
-	"ANA": 24, // Anaheim Ducks
-	"ARI": 53, // Arizona Coyotes
-	"BOS": 1,  // Boston Bruins
-	"BUF": 7,  // Buffalo Sabres
-	"CAR": 12, // Carolina Hurricanes
-	"CBJ": 29, // Columbus Blue Jackets
-	"CGY": 20, // Calgary Flames
-	"CHI": 16, // Chicago Blackhawks
-	"COL": 21, // Colorado Avalanche
-	"DAL": 25, // Dallas Stars
-	"DET": 17, // Detroit Red Wings
-	"EDM": 22, // Edmonton Oilers
-	"FLA": 13, // Florida Panthers
-	"LAK": 26, // Los Angeles Kings
-	"MIN": 30, // Minnesota Wild
-	"MTL": 8,  // Montreal Canadiens
-	"NJD": 6,  // New Jersey Devils
-	"NSH": 18, // Nashville Predators
-	"NYI": 2,  // New York Islanders
-	"NYR": 3,  // New York Rangers
-	"OTT": 9,  // Ottawa Senators
-	"PHI": 4,  // Philadelphia Flyers
-	"PIT": 5,  // Pittsburgh Penguins
-	"SEA": 55, // Seattle Kraken
-	"SJS": 28, // San Jose Sharks
-	"STL": 19, // St. Louis Blues
-	"TBL": 14, // Tampa Bay Lightning
-	"TOR": 10, // Toronto Maple Leafs
-	"VAN": 23, // Vancouver Canucks
-	"VGK": 54, // Vegas Golden Knights
-	"WPG": 52, // Winnipeg Jets
-	"WSH": 15, // Washington Capitals
+var cityCodeToTeamID = teamIDsByCode(nhlTeams)
+
+// teamIDsByCode indexes teams' IDs by city code
+func teamIDsByCode(teams []nhlTeam) map[string]int {
+	ids := make(map[string]int, len(teams))
+	for _, team := range teams {
+		ids[team.Code] = team.ID
+	}
+	return ids
 }
 
 // parseTeamIdentifier converts a team identifier (city code or numeric ID) to a team ID
@@ -156,58 +259,327 @@ func parseTeamIdentifier(identifier string) (int, error) {
 	return teamID, nil
 }
 
-// parseFlags parses and validates command-line flags
-func parseFlags() *Config {
+// teamMapFlag is a repeatable flag of CODE=VALUE pairs keyed by team city code
+type teamMapFlag map[string]string
+
+// String returns the flag's pairs in CODE=VALUE form
+func (f teamMapFlag) String() string {
+	var pairs []string
+	for code, value := range f {
+		pairs = append(pairs, code+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set parses one or more comma-separated CODE=VALUE pairs
+func (f teamMapFlag) Set(s string) error {
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(value) == "" {
+			return fmt.Errorf("invalid team mapping %q (use CODE=VALUE, e.g. DAL=...)", pair)
+		}
+		code = strings.TrimSpace(strings.ToUpper(code))
+		if _, exists := cityCodeToTeamID[code]; !exists {
+			return fmt.Errorf("unknown team city code %q", code)
+		}
+		f[code] = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+// resolveEmulatorHost returns the Cloud Tasks emulator host from the -emulator
+// flag, the CLOUD_TASKS_EMULATOR environment variable, or the default
+func resolveEmulatorHost(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if envHost := os.Getenv("CLOUD_TASKS_EMULATOR"); envHost != "" {
+		return envHost
+	}
+	return "localhost:8123"
+}
+
+// int32Flag is a flag.Value for the int32 fields of the Cloud Tasks API
+type int32Flag struct{ p *int32 }
+
+func (f int32Flag) String() string {
+	if f.p == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*f.p))
+}
+
+func (f int32Flag) Set(s string) error {
+	v, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*f.p = int32(v)
+	return nil
+}
+
+// validateQueueConfig checks the -queue-* settings against the ranges Cloud Tasks accepts
+func validateQueueConfig(q sink.QueueConfig) error {
+	switch {
+	case q.MaxDispatchesPerSecond < 0 || q.MaxDispatchesPerSecond > 500:
+		return fmt.Errorf("-queue-max-dispatches must be between 0 and 500")
+	case q.MaxConcurrentDispatches < 0 || q.MaxConcurrentDispatches > 5000:
+		return fmt.Errorf("-queue-max-concurrent must be between 0 and 5000")
+	case q.MaxAttempts < -1:
+		return fmt.Errorf("-queue-max-attempts must be -1 (unlimited) or more")
+	case q.MinBackoff < 0 || q.MaxBackoff < 0 || q.MaxRetryDuration < 0:
+		return fmt.Errorf("-queue-min-backoff, -queue-max-backoff and -queue-max-retry-duration must not be negative")
+	case q.MinBackoff > 0 && q.MaxBackoff > 0 && q.MinBackoff > q.MaxBackoff:
+		return fmt.Errorf("-queue-min-backoff must not exceed -queue-max-backoff")
+	case q.LogSamplingRatio < 0 || q.LogSamplingRatio > 1:
+		return fmt.Errorf("-queue-log-sampling must be between 0 and 1")
+	}
+	return nil
+}
+
+// parseScheduleFlags parses and validates the schedule command's flags
+func parseScheduleFlags(args []string) *Config {
 	config := &Config{}
 
+	flags := flag.NewFlagSet(os.Args[0]+" schedule", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s schedule (-local | -host URL) [flags]\n\n", os.Args[0])
+		fmt.Fprintf(flags.Output(), "Fetches the NHL schedule for a date and creates a tracking task for each\nmatching game. Running %s with flags and no command also schedules.\n\nFlags:\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+
 	var teamsStr string
 	var emulatorHost string
-	flag.StringVar(&config.Date, "date", "", "Specific date to query (YYYY-MM-DD format). Defaults to today.")
-	flag.StringVar(&teamsStr, "teams", "", "Comma-separated list of team IDs or city codes (e.g., '25,CHI,DAL'). Defaults to Dallas Stars (25).")
-	flag.BoolVar(&config.TestMode, "test", false, "Run in test mode with predefined game ID")
-	flag.BoolVar(&config.AllTeams, "all", false, "Include all teams playing on the specified date")
-	flag.BoolVar(&config.Today, "today", false, "Filter for today's upcoming games only (overrides -date)")
-	flag.BoolVar(&config.Production, "prod", false, "Send tasks to production queue instead of local emulator")
-	flag.BoolVar(&config.Shootout, "shootout", false, "Use shootout game ID (2024030412) instead of default (2024030411)")
-	flag.StringVar(&config.ProjectID, "project", "localproject", "GCP Project ID")
-	flag.StringVar(&config.Location, "location", "us-south1", "GCP Location")
-	flag.StringVar(&config.QueueName, "queue", "gameschedule", "Task Queue name")
-	flag.BoolVar(&config.LocalMode, "local", false, "Send requests to local host (http://host.docker.internal:8080)")
-	flag.StringVar(&config.HostURL, "host", "", "Custom host URL to send requests to")
-	flag.StringVar(&config.DiscordWebhookURL, "discord-webhook", "", "Discord webhook URL for notifications (can also be set via DISCORD_WEBHOOK_URL env var)")
-	flag.StringVar(&emulatorHost, "emulator", "", "Cloud Tasks emulator host (default: localhost:8123 or CLOUD_TASKS_EMULATOR env var)")
-
-	flag.Parse()
+	var discordEvents string
+	var timezone string
+	var failOn string
+	config.TeamWebhooks = make(map[string]string)
+	config.TeamRoles = make(map[string]string)
+	addSlateFlags(flags, config, &teamsStr, &timezone)
+	flags.BoolVar(&config.TestMode, "test", false, "Run in test mode with predefined game ID")
+	flags.BoolVar(&config.Production, "prod", false, "Send tasks to production queue instead of local emulator")
+	flags.BoolVar(&config.Shootout, "shootout", false, "Use shootout game ID (2024030412) instead of default (2024030411)")
+	flags.StringVar(&config.ProjectID, "project", "localproject", "GCP Project ID")
+	flags.StringVar(&config.Location, "location", "us-south1", "GCP Location")
+	flags.StringVar(&config.QueueName, "queue", "gameschedule", "Task Queue name")
+	flags.Float64Var(&config.QueueConfig.MaxDispatchesPerSecond, "queue-max-dispatches", 0, "Queue rate limit in task dispatches per second (0 leaves the queue's setting unchanged)")
+	flags.Var(int32Flag{&config.QueueConfig.MaxConcurrentDispatches}, "queue-max-concurrent", "Queue limit on concurrently dispatched tasks (0 leaves the queue's setting unchanged)")
+	flags.Var(int32Flag{&config.QueueConfig.MaxAttempts}, "queue-max-attempts", "Queue attempts per task including the first, -1 for unlimited (0 leaves the queue's setting unchanged)")
+	flags.DurationVar(&config.QueueConfig.MinBackoff, "queue-min-backoff", 0, "Queue wait before a task's first retry (0 leaves the queue's setting unchanged)")
+	flags.DurationVar(&config.QueueConfig.MaxBackoff, "queue-max-backoff", 0, "Queue cap on the wait between retries (0 leaves the queue's setting unchanged)")
+	flags.DurationVar(&config.QueueConfig.MaxRetryDuration, "queue-max-retry-duration", 0, "Queue time limit for retrying a task (0 leaves the queue's setting unchanged)")
+	flags.Float64Var(&config.QueueConfig.LogSamplingRatio, "queue-log-sampling", 0, "Fraction of task operations the queue logs to Cloud Logging, up to 1 (0 leaves the queue's setting unchanged)")
+	flags.BoolVar(&config.LocalMode, "local", false, "Send requests to local host (http://host.docker.internal:8080)")
+	flags.StringVar(&config.HostURL, "host", "", "Custom host URL to send requests to")
+	flags.StringVar(&config.DiscordWebhookURL, "discord-webhook", "", "Discord webhook URL for notifications (can also be set via DISCORD_WEBHOOK_URL env var)")
+	flags.StringVar(&discordEvents, "discord-events", "", "Comma-separated notification events sent to Discord, or 'all'/'failures' (default: run_completed,run_aborted; can also be set via DISCORD_EVENTS env var)")
+	flags.Var(teamMapFlag(config.TeamWebhooks), "team-webhook", "Per-team Discord webhook as CODE=URL; repeatable (can also be set via DISCORD_TEAM_WEBHOOKS env var)")
+	flags.Var(teamMapFlag(config.TeamRoles), "team-role", "Per-team Discord role ID to mention as CODE=ROLE_ID; repeatable (can also be set via DISCORD_TEAM_ROLES env var)")
+	flags.StringVar(&config.ReminderURL, "reminder-url", "", "Notification relay URL; when set, a pre-game reminder task is created for each game")
+	flags.DurationVar(&config.ReminderLead, "reminder-lead", 30*time.Minute, "How long before puck drop pre-game reminders fire")
+	flags.StringVar(&config.Lang, "lang", "", "Language for notification text: "+strings.Join(notification.SupportedLangs(), ", ")+" (default: en or NOTIFY_LANG env var)")
+	flags.StringVar(&config.MetricsAddr, "metrics-addr", "", "Serve Prometheus metrics on this address during the run, e.g. :9090")
+	flags.StringVar(&config.MetricsTextfile, "metrics-textfile", "", "Write metrics to this node_exporter textfile-collector file (*.prom) when the run ends")
+	flags.StringVar(&config.LogFormat, "log-format", "text", "Log output format: text or json")
+	flags.StringVar(&config.LogLevel, "log-level", "info", "Minimum log level: debug, info, warn or error")
+	flags.StringVar(&config.OTLPEndpoint, "otlp-endpoint", "", "OTLP gRPC collector address for traces, e.g. localhost:4317 (default: OTEL_EXPORTER_OTLP_ENDPOINT env var; empty disables tracing)")
+	flags.BoolVar(&config.OTLPInsecure, "otlp-insecure", false, "Connect to the OTLP collector without TLS (e.g. a local collector)")
+	flags.StringVar(&failOn, "fail-on", string(failOnAny), "Which game failures make the run exit non-zero: any (exit 2 on partial, 3 on total failure), all (exit 3 only if every game failed) or never")
+	flags.IntVar(&config.Concurrency, "concurrency", 4, "Number of games to schedule in parallel")
+	flags.Float64Var(&config.RateLimit, "rate-limit", 10, "Maximum task sink requests per second across all workers (0 for unlimited)")
+	flags.IntVar(&config.RPCAttempts, "rpc-attempts", grpcretry.DefaultPolicy().MaxAttempts, "Attempts per Cloud Tasks RPC when it fails with Unavailable, DeadlineExceeded or ResourceExhausted")
+	flags.DurationVar(&config.DialTimeout, "dial-timeout", 10*time.Second, "How long to wait for the Cloud Tasks connection and health check")
+	flags.BoolVar(&config.TLS, "tls", false, "Connect to Cloud Tasks over TLS, verifying the server against the system roots")
+	flags.StringVar(&config.TLSCAFile, "tls-ca-file", "", "PEM CA bundle to verify the Cloud Tasks server with (implies -tls)")
+	flags.StringVar(&config.Sink, "sink", SinkCloudTasks, "Task sink: "+strings.Join(sinkKinds, ", ")+" (memory is a dry run that lists tasks without delivering them; local delivers them from this process; pubsub publishes payloads to -pubsub-topic; redis enqueues asynq jobs in -redis-addr)")
+	flags.StringVar(&config.LocalStatePath, "local-state", "gametask-local-tasks.json", "File the local sink persists pending tasks to")
+	flags.StringVar(&config.PubSubTopic, "pubsub-topic", "gameschedule", "Pub/Sub topic the pubsub sink publishes to, in -project")
+	flags.StringVar(&config.PubSubEmulator, "pubsub-emulator", "", "Pub/Sub emulator host for the pubsub sink outside -prod (default: PUBSUB_EMULATOR_HOST env var)")
+	flags.StringVar(&config.RedisAddr, "redis-addr", "", "Redis address for the redis sink (default: localhost:6379 or REDIS_ADDR env var; password from REDIS_PASSWORD)")
+	flags.IntVar(&config.RedisDB, "redis-db", 0, "Redis database number for the redis sink")
+	flags.StringVar(&config.RedisQueue, "redis-queue", "default", "asynq queue the redis sink enqueues jobs on")
+	flags.StringVar(&config.HistoryPath, "history-db", "", "Database recording each scheduling decision; games unchanged since they were last scheduled are skipped (can also be set via HISTORY_DB env var; disabled if neither is set)")
+	flags.BoolVar(&config.Force, "force", false, "Schedule games even if -history-db shows them unchanged, replacing their earlier tasks")
+	flags.StringVar(&emulatorHost, "emulator", "", "Cloud Tasks emulator host (default: localhost:8123 or CLOUD_TASKS_EMULATOR env var)")
+
+	// Invalid flags are configuration errors; flag's default exit status of 2
+	// would read as a partial failure
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(ExitOK)
+		}
+		os.Exit(ExitConfigError)
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: unexpected arguments: %v\n", flags.Args())
+		flags.Usage()
+		os.Exit(ExitConfigError)
+	}
+
+	// Configure structured logging before anything else is logged; every
+	// record carries the run ID so one run's lines can be grouped
+	config.RunID = newRunID()
+	logger, err := newLogger(os.Stderr, config.LogFormat, config.LogLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(ExitConfigError)
+	}
+	slog.SetDefault(logger.With("run_id", config.RunID))
 
 	// Check for Discord webhook URL from environment variable if not set via flag
 	if config.DiscordWebhookURL == "" {
 		config.DiscordWebhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
 	}
 
-	// Set emulator host from flag, environment variable, or default
-	if emulatorHost != "" {
-		config.EmulatorHost = emulatorHost
-	} else if envHost := os.Getenv("CLOUD_TASKS_EMULATOR"); envHost != "" {
-		config.EmulatorHost = envHost
-	} else {
-		config.EmulatorHost = "localhost:8123"
+	// Parse Discord event subscription from flag or environment variable
+	if discordEvents == "" {
+		discordEvents = os.Getenv("DISCORD_EVENTS")
+	}
+	kinds, err := notification.ParseEventKinds(discordEvents)
+	if err != nil {
+		fatal(ExitConfigError, "Invalid -discord-events", "error", err)
+	}
+	config.DiscordEvents = kinds
+
+	config.FailOn, err = parseFailOn(failOn)
+	if err != nil {
+		fatal(ExitConfigError, "Invalid -fail-on", "error", err)
+	}
+
+	if config.Concurrency < 1 {
+		fatal(ExitConfigError, "-concurrency must be at least 1")
+	}
+	if !slices.Contains(sinkKinds, config.Sink) {
+		fatal(ExitConfigError, "Invalid -sink", "sink", config.Sink, "supported", sinkKinds)
+	}
+	if config.DialTimeout <= 0 {
+		fatal(ExitConfigError, "-dial-timeout must be positive")
+	}
+	if err := validateQueueConfig(config.QueueConfig); err != nil {
+		fatal(ExitConfigError, "Invalid queue settings", "error", err)
+	}
+	if config.TLSCAFile != "" {
+		config.TLS = true
+	}
+	if config.RPCAttempts < 1 {
+		fatal(ExitConfigError, "-rpc-attempts must be at least 1")
+	}
+	if config.RateLimit < 0 {
+		fatal(ExitConfigError, "-rate-limit must not be negative")
+	}
+
+	// Resolve the notification message catalog from flag or environment variable
+	if config.Lang == "" {
+		config.Lang = os.Getenv("NOTIFY_LANG")
+	}
+	config.Messages, err = notification.MessagesFor(config.Lang)
+	if err != nil {
+		fatal(ExitConfigError, "Invalid -lang", "error", err)
+	}
+
+	// Merge per-team routing from environment variables, flags taking precedence
+	for envVar, target := range map[string]map[string]string{
+		"DISCORD_TEAM_WEBHOOKS": config.TeamWebhooks,
+		"DISCORD_TEAM_ROLES":    config.TeamRoles,
+	} {
+		fromEnv := teamMapFlag{}
+		if err := fromEnv.Set(os.Getenv(envVar)); err != nil {
+			fatal(ExitConfigError, "Invalid team mapping", "env", envVar, "error", err)
+		}
+		for code, value := range fromEnv {
+			if _, set := target[code]; !set {
+				target[code] = value
+			}
+		}
+	}
+	for code := range config.TeamRoles {
+		if _, ok := config.TeamWebhooks[code]; !ok {
+			fatal(ExitConfigError, "-team-role given without a -team-webhook", "team", code)
+		}
+	}
+
+	// History must outlive the run to be useful, so it is only kept where asked
+	if config.HistoryPath == "" {
+		config.HistoryPath = os.Getenv("HISTORY_DB")
 	}
 
-	// Validate that either -local or -host is provided
-	if !config.LocalMode && config.HostURL == "" {
-		log.Fatalf("Error: Either -local or -host <url> must be provided")
+	config.EmulatorHost = resolveEmulatorHost(emulatorHost)
+
+	// Pub/Sub messages and Redis jobs have no target URL; every other sink needs -local or -host
+	switch config.Sink {
+	case SinkRedis:
+		if config.RedisAddr == "" {
+			config.RedisAddr = os.Getenv("REDIS_ADDR")
+		}
+		if config.RedisAddr == "" {
+			config.RedisAddr = "localhost:6379"
+		}
+		config.RedisPassword = os.Getenv("REDIS_PASSWORD")
+		if config.RedisQueue == "" {
+			fatal(ExitConfigError, "-redis-queue must not be empty")
+		}
+	case SinkPubSub:
+		if config.PubSubEmulator == "" {
+			config.PubSubEmulator = os.Getenv("PUBSUB_EMULATOR_HOST")
+		}
+		if !config.Production && config.PubSubEmulator == "" {
+			fatal(ExitConfigError, "-sink pubsub needs -pubsub-emulator or PUBSUB_EMULATOR_HOST unless -prod is set")
+		}
+		if config.PubSubTopic == "" {
+			fatal(ExitConfigError, "-pubsub-topic must not be empty")
+		}
+	default:
+		if !config.LocalMode && config.HostURL == "" {
+			fatal(ExitConfigError, "Either -local or -host <url> must be provided")
+		}
 	}
 
 	// Validate that both -local and -host are not provided at the same time
 	if config.LocalMode && config.HostURL != "" {
-		log.Fatalf("Error: Cannot specify both -local and -host flags")
+		fatal(ExitConfigError, "Cannot specify both -local and -host flags")
+	}
+
+	if config.ReminderURL != "" && config.ReminderLead <= 0 {
+		fatal(ExitConfigError, "-reminder-lead must be positive")
+	}
+
+	resolveSlate(config, teamsStr, timezone)
+
+	return config
+}
+
+// addSlateFlags registers the flags that pick a day's games: -date, -today,
+// -teams, -all and -tz. Call resolveSlate after parsing.
+func addSlateFlags(flags *flag.FlagSet, config *Config, teams, timezone *string) {
+	flags.StringVar(&config.Date, "date", "", "Specific date to query (YYYY-MM-DD format). Defaults to today.")
+	flags.StringVar(teams, "teams", "", "Comma-separated list of team IDs or city codes (e.g., '25,CHI,DAL'). Defaults to Dallas Stars (25).")
+	flags.BoolVar(&config.AllTeams, "all", false, "Include all teams playing on the specified date")
+	flags.BoolVar(&config.Today, "today", false, "Filter for today's upcoming games only (overrides -date)")
+	flags.StringVar(timezone, "tz", "", "IANA time zone that defines 'today' and displayed times, e.g. America/Chicago (default: host local time zone)")
+}
+
+// resolveSlate validates the slate flags, setting config's time zone, date
+// and teams
+func resolveSlate(config *Config, teamsStr, timezone string) {
+	var err error
+
+	// Resolve the time zone, so "today" is the same slate wherever the container runs
+	config.TimeZone = time.Local
+	if timezone != "" {
+		config.TimeZone, err = time.LoadLocation(timezone)
+		if err != nil {
+			fatal(ExitConfigError, "Invalid -tz", "error", err)
+		}
 	}
 
 	// Handle today flag - overrides date setting
 	if config.Today {
-		config.Date = time.Now().Format("2006-01-02")
+		config.Date = time.Now().In(config.TimeZone).Format("2006-01-02")
 	} else if config.Date == "" {
-		config.Date = time.Now().Format("2006-01-02")
+		config.Date = time.Now().In(config.TimeZone).Format("2006-01-02")
 	}
 
 	// Parse team IDs
@@ -219,46 +591,61 @@ func parseFlags() *Config {
 		for i, teamStr := range teamStrs {
 			teamID, err := parseTeamIdentifier(teamStr)
 			if err != nil {
-				log.Fatalf("Invalid team identifier: %s", err)
+				fatal(ExitConfigError, "Invalid team identifier", "error", err)
 			}
 			config.Teams[i] = teamID
 		}
 	} else {
 		config.Teams = []int{DefaultTeamID} // Default to Dallas Stars
 	}
-
-	return config
 }
 
 // fetchGamesForDate retrieves games for a specific date from the NHL API
-func fetchGamesForDate(date string) ([]Game, error) {
+func fetchGamesForDate(ctx context.Context, date string) (games []Game, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "fetchGamesForDate", trace.WithAttributes(attribute.String("game.date", date)))
+	defer func() {
+		tracing.RecordError(span, err)
+		span.SetAttributes(attribute.Int("games.count", len(games)))
+		span.End()
+	}()
+
 	url := fmt.Sprintf("%s/schedule/%s", NHLAPIBaseURL, date)
 
-	log.Printf("Fetching games from NHL API: %s", url)
+	slog.Info("Fetching games from NHL API", "url", url, "date", date)
 
-	resp, err := http.Get(url)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
+		return nil, fmt.Errorf("failed to create schedule request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := nhlClient.Do(req)
+	if err != nil {
+		runMetrics.ObserveNHLAPI(start, err)
 		return nil, fmt.Errorf("failed to fetch schedule: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("NHL API returned status: %d", resp.StatusCode)
+		err := fmt.Errorf("NHL API returned status: %d", resp.StatusCode)
+		runMetrics.ObserveNHLAPI(start, err)
+		return nil, err
 	}
+	runMetrics.ObserveNHLAPI(start, nil)
 
 	var schedule ScheduleResponse
 	if err := json.NewDecoder(resp.Body).Decode(&schedule); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	var games []Game
 	for _, week := range schedule.GameWeek {
 		for _, game := range week.Games {
 			games = append(games, game)
 		}
 	}
 
-	log.Printf("Found %d games for date %s", len(games), date)
+	runMetrics.GamesFetched.Add(float64(len(games)))
+	slog.Info("Found games", "count", len(games), "date", date)
 	return games, nil
 }
 
@@ -281,7 +668,8 @@ func filterGamesForTeams(games []Game, teams []int) []Game {
 		}
 	}
 
-	log.Printf("Filtered to %d games involving specified teams", len(filteredGames))
+	runMetrics.GamesFiltered.WithLabelValues("team").Add(float64(len(games) - len(filteredGames)))
+	slog.Info("Filtered games involving specified teams", "count", len(filteredGames))
 	return filteredGames
 }
 
@@ -293,7 +681,7 @@ func filterUpcomingGames(games []Game) []Game {
 	for _, game := range games {
 		startTime, err := time.Parse(time.RFC3339, game.StartTime)
 		if err != nil {
-			log.Printf("Warning: Could not parse start time for game %d: %v", game.ID, err)
+			slog.Warn("Could not parse start time", "game_id", game.ID, "error", err)
 			continue
 		}
 
@@ -303,7 +691,8 @@ func filterUpcomingGames(games []Game) []Game {
 		}
 	}
 
-	log.Printf("Filtered to %d upcoming games", len(upcomingGames))
+	runMetrics.GamesFiltered.WithLabelValues("started").Add(float64(len(games) - len(upcomingGames)))
+	slog.Info("Filtered upcoming games", "count", len(upcomingGames))
 	return upcomingGames
 }
 
@@ -347,124 +736,230 @@ func createTestGame(shootout bool) Game {
 	}
 }
 
-// createQueue creates a task queue if it doesn't exist
-func createQueue(client taskspb.CloudTasksClient, ctx context.Context, config *Config) error {
-	// projects/localproject/locations/us-south1/queues/gameschedule
-	queuePath := fmt.Sprintf("projects/%s/locations/%s/queues/%s", config.ProjectID, config.Location, config.QueueName)
-	parentPath := fmt.Sprintf("projects/%s/locations/%s", config.ProjectID, config.Location)
-
-	req := &taskspb.CreateQueueRequest{
-		Parent: parentPath,
-		Queue: &taskspb.Queue{
-			Name: queuePath,
+// newTaskGameInfo builds the task payload's game information from an NHL API game
+func newTaskGameInfo(game Game) GameInfo {
+	return GameInfo{
+		ID:        strconv.Itoa(game.ID),
+		GameDate:  game.GameDate,
+		StartTime: game.StartTime,
+		HomeTeam: Team{
+			ID:                       game.HomeTeam.ID,
+			CommonName:               game.HomeTeam.CommonName,
+			PlaceName:                game.HomeTeam.PlaceName,
+			PlaceNameWithPreposition: game.HomeTeam.PlaceNameWithPreposition,
+			Abbrev:                   game.HomeTeam.Abbrev,
+		},
+		AwayTeam: Team{
+			ID:                       game.AwayTeam.ID,
+			CommonName:               game.AwayTeam.CommonName,
+			PlaceName:                game.AwayTeam.PlaceName,
+			PlaceNameWithPreposition: game.AwayTeam.PlaceNameWithPreposition,
+			Abbrev:                   game.AwayTeam.Abbrev,
 		},
 	}
-	_, err := client.CreateQueue(ctx, req)
+}
+
+// createGameTask schedules the tracking task for a given game on the task sink
+func createGameTask(ctx context.Context, taskSink sink.TaskSink, config *Config, game Game) (sink.Task, error) {
+	startTime, err := time.Parse(time.RFC3339, game.StartTime)
 	if err != nil {
-		if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "AlreadyExists") {
-			log.Printf("Queue %s already exists, skipping creation", config.QueueName)
-			return nil
-		}
-		return fmt.Errorf("failed to create queue: %w", err)
+		return sink.Task{}, fmt.Errorf("failed to parse start time: %w", err)
 	}
-	log.Printf("Created queue: %s", queuePath)
-	return nil
+
+	payload := newTaskPayload(config, game, startTime)
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return sink.Task{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	scheduleTime := gameTaskScheduleTime(startTime)
+
+	attributes := map[string]string{"gameId": strconv.Itoa(game.ID), "executionEnd": *payload.ExecutionEnd}
+	task, err := createHTTPTask(ctx, taskSink, config, gameTaskURL(config), payloadJSON, scheduleTime, attributes)
+	if err != nil {
+		return sink.Task{}, err
+	}
+
+	slog.Info("Created task", "game_id", game.ID, "task", task.Name, "schedule_time", scheduleTime.Format(time.RFC3339))
+	return task, nil
 }
 
-// createCloudTask creates a Google Cloud Task for a given game using direct GRPC
-func createCloudTask(ctx context.Context, client taskspb.CloudTasksClient, config *Config, game Game) error {
+// newTaskPayload builds the tracking task's payload for a game starting at startTime
+func newTaskPayload(config *Config, game Game, startTime time.Time) TaskPayload {
 	// Create execution end time (game start time + 4 hours for typical game duration)
+	executionEnd := startTime.Add(4 * time.Hour).Format(time.RFC3339)
+
+	return TaskPayload{
+		Game:         newTaskGameInfo(game),
+		ExecutionEnd: &executionEnd,
+		ShouldNotify: !config.TestMode,
+	}
+}
+
+// gameTaskURL returns the URL tracking tasks are sent to, based on host configuration
+func gameTaskURL(config *Config) string {
+	if config.LocalMode {
+		return "http://host.docker.internal:8080"
+	}
+	return config.HostURL
+}
+
+// gameTaskScheduleTime returns when a game's tracking task runs: 5 minutes
+// before the game starts
+func gameTaskScheduleTime(startTime time.Time) time.Time {
+	return startTime.Add(-5 * time.Minute)
+}
+
+// reminderScheduleTime returns when a game's reminder fires: lead before the
+// game starts
+func reminderScheduleTime(startTime time.Time, lead time.Duration) time.Time {
+	return startTime.Add(-lead)
+}
+
+// createReminderTask schedules a task that asks the notification relay to
+// announce a game config.ReminderLead before puck drop. Reminders whose time
+// has already passed are skipped, returning an empty task.
+func createReminderTask(ctx context.Context, taskSink sink.TaskSink, config *Config, game Game) (sink.Task, error) {
 	startTime, err := time.Parse(time.RFC3339, game.StartTime)
 	if err != nil {
-		return fmt.Errorf("failed to parse start time: %w", err)
+		return sink.Task{}, fmt.Errorf("failed to parse start time: %w", err)
 	}
 
-	executionEnd := startTime.Add(4 * time.Hour).Format(time.RFC3339)
+	scheduleTime := reminderScheduleTime(startTime, config.ReminderLead)
+	if scheduleTime.Before(time.Now()) {
+		slog.Info("Skipping reminder, reminder time has passed", "game_id", game.ID, "schedule_time", scheduleTime.Format(time.RFC3339))
+		return sink.Task{}, nil
+	}
 
-	// Prepare the task payload with full game context
-	payload := TaskPayload{
-		Game: GameInfo{
-			ID:        strconv.Itoa(game.ID),
-			GameDate:  game.GameDate,
-			StartTime: game.StartTime,
-			HomeTeam: Team{
-				ID:                       game.HomeTeam.ID,
-				CommonName:               game.HomeTeam.CommonName,
-				PlaceName:                game.HomeTeam.PlaceName,
-				PlaceNameWithPreposition: game.HomeTeam.PlaceNameWithPreposition,
-				Abbrev:                   game.HomeTeam.Abbrev,
-			},
-			AwayTeam: Team{
-				ID:                       game.AwayTeam.ID,
-				CommonName:               game.AwayTeam.CommonName,
-				PlaceName:                game.AwayTeam.PlaceName,
-				PlaceNameWithPreposition: game.AwayTeam.PlaceNameWithPreposition,
-				Abbrev:                   game.AwayTeam.Abbrev,
-			},
-		},
-		ExecutionEnd: &executionEnd,
+	payload := ReminderPayload{
+		Type:         ReminderPayloadType,
+		Game:         newTaskGameInfo(game),
+		LeadMinutes:  int(config.ReminderLead.Minutes()),
+		Message:      config.Messages.ReminderText(game.AwayTeam.Abbrev+" @ "+game.HomeTeam.Abbrev, int(config.ReminderLead.Minutes())),
 		ShouldNotify: !config.TestMode,
 	}
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return sink.Task{}, fmt.Errorf("failed to marshal reminder payload: %w", err)
 	}
 
-	// Determine the target URL based on host configuration
-	var targetURL string
-	if config.LocalMode {
-		targetURL = "http://host.docker.internal:8080"
-	} else {
-		targetURL = config.HostURL
-	}
-
-	// Schedule task to run 5 minutes before game start
-	scheduleTime := startTime.Add(-5 * time.Minute)
-
-	// Create the task request using taskspb format (works for emulator)
-	queuePath := fmt.Sprintf("projects/%s/locations/%s/queues/%s", config.ProjectID, config.Location, config.QueueName)
-
-	req := &taskspb.CreateTaskRequest{
-		Parent: queuePath,
-		Task: &taskspb.Task{
-			MessageType: &taskspb.Task_HttpRequest{
-				HttpRequest: &taskspb.HttpRequest{
-					HttpMethod: taskspb.HttpMethod_POST,
-					Url:        targetURL,
-					Headers: map[string]string{
-						"Content-Type": "application/json",
-					},
-					Body: payloadJSON,
-				},
-			},
-			ScheduleTime: timestamppb.New(scheduleTime),
-		},
+	attributes := map[string]string{"gameId": strconv.Itoa(game.ID), "type": ReminderPayloadType}
+	task, err := createHTTPTask(ctx, taskSink, config, config.ReminderURL, payloadJSON, scheduleTime, attributes)
+	if err != nil {
+		return sink.Task{}, fmt.Errorf("failed to create reminder: %w", err)
+	}
+
+	slog.Info("Created reminder task", "game_id", game.ID, "task", task.Name, "schedule_time", scheduleTime.Format(time.RFC3339))
+	return task, nil
+}
+
+// createHTTPTask schedules a task on the sink that POSTs body as JSON to targetURL at scheduleTime.
+// Attributes describe the task to message-based sinks; the run ID is added to them.
+// The task is named after its content, so a retried or repeated request finds
+// the task already there instead of creating a second one
+func createHTTPTask(ctx context.Context, taskSink sink.TaskSink, config *Config, targetURL string, body []byte, scheduleTime time.Time, attributes map[string]string) (sink.Task, error) {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		RunIDHeader:    config.RunID,
+	}
+	// Carry the trace context so the tracker's spans join this run's trace
+	tracing.Inject(ctx, headers)
+
+	req := sink.Task{
+		Name:         taskName(attributes, targetURL, body, scheduleTime),
+		URL:          targetURL,
+		Method:       http.MethodPost,
+		Headers:      headers,
+		Body:         body,
+		ScheduleTime: scheduleTime,
+		Attributes:   attributes,
+	}
+	if req.Attributes == nil {
+		req.Attributes = make(map[string]string)
 	}
+	req.Attributes["runId"] = config.RunID
 
-	// Create the task
-	task, err := client.CreateTask(ctx, req)
+	task, err := taskSink.Schedule(ctx, req)
 	if err != nil {
-		return fmt.Errorf("failed to create task: %w", err)
+		// A task that already exists was scheduled by an earlier attempt or run;
+		// report it by the name the sink knows it by
+		if errors.Is(err, sink.ErrAlreadyExists) {
+			existing := req
+			if task.Name != "" {
+				existing.Name = task.Name
+			}
+			runMetrics.TasksAlreadyExisting.Inc()
+			slog.Info("Task already exists", "task", existing.Name)
+			return existing, nil
+		}
+		runMetrics.TasksFailed.Inc()
+		return sink.Task{}, err
 	}
 
-	log.Printf("Created task %s for game %d, scheduled for %s", task.Name, game.ID, scheduleTime.Format(time.RFC3339))
-	return nil
+	runMetrics.TasksCreated.Inc()
+	return task, nil
+}
+
+// taskName derives a task's name from what it does: its kind and game, then a
+// hash of its target, payload and schedule time. A game whose start time or
+// payload changes gets a new name, while the same task always gets the same one.
+func taskName(attributes map[string]string, targetURL string, body []byte, scheduleTime time.Time) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%d\n", targetURL, scheduleTime.Unix())
+	hash.Write(body)
+	sum := hex.EncodeToString(hash.Sum(nil))[:16]
+
+	kind := attributes["type"]
+	if kind == "" {
+		kind = "game"
+	}
+	if id := attributes["gameId"]; id != "" {
+		return kind + "-" + id + "-" + sum
+	}
+	return kind + "-" + sum
 }
 
 // connectToTasksService connects to Cloud Tasks service (emulator or production)
+// and checks that it answers before any scheduling begins
 func connectToTasksService(ctx context.Context, config *Config) (taskspb.CloudTasksClient, *grpc.ClientConn, error) {
 	if !config.Production {
 		// Connect to local emulator using direct GRPC (like localCloudTasksTest)
 		endpoint := config.EmulatorHost
-		log.Printf("Connecting to local Cloud Tasks emulator at %s", endpoint)
+		slog.Info("Connecting to local Cloud Tasks emulator", "endpoint", endpoint, "tls", config.TLS, "timeout", config.DialTimeout)
 
-		conn, err := grpc.DialContext(ctx, endpoint, grpc.WithInsecure(), grpc.WithBlock())
+		credentials, err := transportCredentials(config)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to connect to local Cloud Tasks emulator at %s - ensure the emulator is running: %w", endpoint, err)
+			return nil, nil, err
+		}
+
+		// Bound the dial so a missing emulator fails the run instead of hanging it
+		dialCtx, cancel := context.WithTimeout(ctx, config.DialTimeout)
+		defer cancel()
+
+		conn, err := grpc.DialContext(dialCtx, endpoint,
+			grpc.WithTransportCredentials(credentials),
+			grpc.WithBlock(),
+			grpc.WithReturnConnectionError(),
+			grpc.WithKeepaliveParams(keepalive.ClientParameters{
+				Time:    30 * time.Second,
+				Timeout: 10 * time.Second,
+			}),
+			grpc.WithChainUnaryInterceptor(
+				grpcretry.UnaryClientInterceptor(retryPolicy(config)),
+				rateLimitInterceptor(newRateLimiter(config)),
+				runMetrics.UnaryClientInterceptor(),
+			),
+			tracing.DialOption())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to local Cloud Tasks emulator at %s within %s - ensure the emulator is running: %w", endpoint, config.DialTimeout, err)
 		}
 
 		client := taskspb.NewCloudTasksClient(conn)
+		if err := checkTasksService(ctx, client, config); err != nil {
+			conn.Close()
+			return nil, nil, err
+		}
 		return client, conn, nil
 	} else {
 		// For production mode, we would need to implement the official client approach
@@ -473,73 +968,681 @@ func connectToTasksService(ctx context.Context, config *Config) (taskspb.CloudTa
 	}
 }
 
-// processGames processes a list of games and creates cloud tasks for each
-func processGames(ctx context.Context, client taskspb.CloudTasksClient, config *Config, games []Game) error {
+// transportCredentials returns TLS credentials when -tls or -tls-ca-file is set,
+// verifying the server against the CA file or the system roots, and insecure
+// credentials otherwise
+func transportCredentials(config *Config) (credentials.TransportCredentials, error) {
+	if !config.TLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if config.TLSCAFile != "" {
+		pem, err := os.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file %s", config.TLSCAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// checkTasksService probes the service with a ListQueues call, so an
+// unreachable or misconfigured service is reported before any game is scheduled
+func checkTasksService(ctx context.Context, client taskspb.CloudTasksClient, config *Config) error {
+	probeCtx, cancel := context.WithTimeout(ctx, config.DialTimeout)
+	defer cancel()
+
+	parentPath := fmt.Sprintf("projects/%s/locations/%s", config.ProjectID, config.Location)
+	if _, err := client.ListQueues(probeCtx, &taskspb.ListQueuesRequest{Parent: parentPath, PageSize: 1}); err != nil {
+		return fmt.Errorf("Cloud Tasks health check failed (ListQueues %s): %w", parentPath, err)
+	}
+
+	slog.Info("Cloud Tasks health check passed", "parent", parentPath)
+	return nil
+}
+
+// gameResult records the outcome of processing a single game
+type gameResult struct {
+	Game   Game
+	Status notification.GameStatus
+	Reason string // Why the game was skipped or failed
+	Err    error  // Error that caused the game to fail
+
+	PreviousStartTime string // Start time the game was last scheduled with, if it has since changed
+}
+
+// retryPolicy returns the retry policy for Cloud Tasks RPCs; every attempt
+// passes through the rate limiter and is recorded in metrics
+func retryPolicy(config *Config) grpcretry.Policy {
+	policy := grpcretry.DefaultPolicy()
+	policy.MaxAttempts = config.RPCAttempts
+	return policy
+}
+
+// Task sinks selectable with -sink
+const (
+	SinkCloudTasks = "cloudtasks"
+	SinkMemory     = "memory"
+	SinkLocal      = "local"
+	SinkPubSub     = "pubsub"
+	SinkRedis      = "redis"
+)
+
+// sinkKinds lists the supported -sink values
+var sinkKinds = []string{SinkCloudTasks, SinkMemory, SinkLocal, SinkPubSub, SinkRedis}
+
+// newRateLimiter returns the limiter shared by every task sink request of the run
+func newRateLimiter(config *Config) *rate.Limiter {
+	if config.RateLimit <= 0 {
+		return rate.NewLimiter(rate.Inf, 0)
+	}
+	return rate.NewLimiter(rate.Limit(config.RateLimit), config.Concurrency)
+}
+
+// rateLimitInterceptor holds each RPC until the limiter allows it, so all
+// workers together stay within the queue's API quota
+func rateLimitInterceptor(limiter *rate.Limiter) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// rateLimitedSink holds each call to a sink until the limiter allows it. Cloud
+// Tasks is paced by rateLimitInterceptor instead, which also covers its retries
+type rateLimitedSink struct {
+	sink.TaskSink
+	limiter *rate.Limiter
+}
+
+// limitSink paces taskSink with -rate-limit, or returns it unchanged when
+// the rate is unlimited
+func limitSink(taskSink sink.TaskSink, config *Config) sink.TaskSink {
+	if config.RateLimit <= 0 {
+		return taskSink
+	}
+	return &rateLimitedSink{TaskSink: taskSink, limiter: newRateLimiter(config)}
+}
+
+func (r *rateLimitedSink) Schedule(ctx context.Context, task sink.Task) (sink.Task, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return sink.Task{}, err
+	}
+	return r.TaskSink.Schedule(ctx, task)
+}
+
+func (r *rateLimitedSink) Cancel(ctx context.Context, name string) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return r.TaskSink.Cancel(ctx, name)
+}
+
+func (r *rateLimitedSink) List(ctx context.Context) ([]sink.Task, error) {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return r.TaskSink.List(ctx)
+}
+
+// newTaskSink creates the task sink selected by -sink; the returned close
+// function releases its connection, or for the local sink waits for its
+// delivery loop to stop
+func newTaskSink(ctx context.Context, config *Config) (sink.TaskSink, func() error, error) {
+	switch config.Sink {
+	case SinkMemory:
+		slog.Info("Using in-memory task sink; tasks will be listed but not delivered")
+		return sink.NewMemory(), func() error { return nil }, nil
+	case SinkLocal:
+		local, err := sink.NewLocal(sink.LocalOptions{StatePath: config.LocalStatePath})
+		if err != nil {
+			return nil, nil, err
+		}
+		pending, _ := local.List(ctx)
+		slog.Info("Using local task sink; tasks are delivered by this process", "state", config.LocalStatePath, "pending", len(pending))
+
+		done := make(chan error, 1)
+		go func() { done <- local.Run(ctx) }()
+		return limitSink(local, config), sync.OnceValue(func() error { return <-done }), nil
+	case SinkPubSub:
+		pubsub, closePubSub, err := newPubSubSink(ctx, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		return limitSink(pubsub, config), closePubSub, nil
+	case SinkRedis:
+		redis := sink.NewRedis(sink.RedisOptions{
+			Addr:     config.RedisAddr,
+			Password: config.RedisPassword,
+			DB:       config.RedisDB,
+			Queue:    config.RedisQueue,
+		})
+		if err := redis.Check(); err != nil {
+			redis.Close()
+			return nil, nil, err
+		}
+		slog.Info("Using Redis task sink; tasks are enqueued as asynq jobs", "addr", config.RedisAddr, "queue", config.RedisQueue)
+		return limitSink(redis, config), redis.Close, nil
+	default:
+		// Connect to Cloud Tasks service (emulator or production)
+		client, conn, err := connectToTasksService(ctx, config)
+		if err != nil {
+			return nil, nil, err
+		}
+		cloudTasks := sink.NewCloudTasks(client, config.ProjectID, config.Location, config.QueueName)
+
+		// Create the queue, or update it to match the configured settings
+		created, changes, err := cloudTasks.EnsureQueue(ctx, config.QueueConfig)
+		switch {
+		case err != nil:
+			slog.Warn("Failed to create or update queue", "error", err)
+		case created:
+			slog.Info("Created queue", "queue", cloudTasks.QueuePath())
+		case len(changes) > 0:
+			slog.Info("Updated queue to match configuration", "queue", cloudTasks.QueuePath(), "changes", len(changes))
+		default:
+			slog.Info("Queue already exists and matches configuration", "queue", config.QueueName)
+		}
+		for _, change := range changes {
+			slog.Info("Queue setting", "field", change.Field, "from", change.From, "to", change.To)
+		}
+		return cloudTasks, conn.Close, nil
+	}
+}
+
+// sinkTarget identifies where the configured sink delivers tasks, so history
+// recorded against the emulator, another queue or another sink is kept apart
+func sinkTarget(config *Config) string {
+	switch config.Sink {
+	case SinkLocal:
+		return "local:" + config.LocalStatePath
+	case SinkPubSub:
+		target := fmt.Sprintf("pubsub:projects/%s/topics/%s", config.ProjectID, config.PubSubTopic)
+		if !config.Production {
+			target += "@" + config.PubSubEmulator
+		}
+		return target
+	case SinkRedis:
+		return fmt.Sprintf("redis:%s/%d/%s", config.RedisAddr, config.RedisDB, config.RedisQueue)
+	default:
+		target := fmt.Sprintf("cloudtasks:projects/%s/locations/%s/queues/%s", config.ProjectID, config.Location, config.QueueName)
+		if !config.Production {
+			target += "@" + config.EmulatorHost
+		}
+		return target
+	}
+}
+
+// newPubSubSink connects to Pub/Sub (the emulator, or production with
+// application default credentials under -prod) and makes sure the topic exists
+func newPubSubSink(ctx context.Context, config *Config) (sink.TaskSink, func() error, error) {
+	var opts []option.ClientOption
+	var conn *grpc.ClientConn
+	if !config.Production {
+		slog.Info("Connecting to Pub/Sub emulator", "endpoint", config.PubSubEmulator, "timeout", config.DialTimeout)
+
+		dialCtx, cancel := context.WithTimeout(ctx, config.DialTimeout)
+		defer cancel()
+
+		var err error
+		conn, err = grpc.DialContext(dialCtx, config.PubSubEmulator,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithBlock(),
+			grpc.WithReturnConnectionError(),
+			tracing.DialOption())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to connect to Pub/Sub emulator at %s within %s - ensure the emulator is running: %w", config.PubSubEmulator, config.DialTimeout, err)
+		}
+		opts = append(opts, option.WithGRPCConn(conn))
+	}
+
+	client, err := pubsub.NewClient(ctx, config.ProjectID, opts...)
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, nil, fmt.Errorf("failed to create Pub/Sub client: %w", err)
+	}
+	closeSink := func() error {
+		err := client.Close()
+		if conn != nil {
+			conn.Close()
+		}
+		return err
+	}
+
+	pubSub := sink.NewPubSub(client, config.PubSubTopic)
+	created, err := pubSub.EnsureTopic(ctx)
+	switch {
+	case err != nil:
+		slog.Warn("Failed to create topic", "error", err)
+	case created:
+		slog.Info("Created topic", "topic", pubSub.TopicPath())
+	default:
+		slog.Info("Topic already exists, skipping creation", "topic", pubSub.TopicPath())
+	}
+	return pubSub, func() error {
+		pubSub.Stop()
+		return closeSink()
+	}, nil
+}
+
+// logPendingTasks logs every task held by the sink, for dry runs
+func logPendingTasks(ctx context.Context, taskSink sink.TaskSink) {
+	tasks, err := taskSink.List(ctx)
+	if err != nil {
+		slog.Warn("Failed to list tasks", "error", err)
+		return
+	}
+	for _, task := range tasks {
+		slog.Info("Pending task", "task", task.Name, "url", task.URL, "schedule_time", task.ScheduleTime.Format(time.RFC3339), "body", string(task.Body))
+	}
+}
+
+// processGames processes a list of games and creates tasks for each on the task sink.
+// It returns one result per game, in the order the games were given.
+func processGames(ctx context.Context, taskSink sink.TaskSink, config *Config, games []Game) ([]gameResult, error) {
 	if len(games) == 0 {
-		log.Println("No games found to process")
-		return nil
+		slog.Info("No games found to process")
+		return nil, nil
 	}
 
-	// Create queue if it doesn't exist
-	if err := createQueue(client, ctx, config); err != nil {
-		log.Printf("Warning: Failed to create queue: %v", err)
+	workers := min(config.Concurrency, len(games))
+	slog.Info("Processing games", "count", len(games), "concurrency", workers)
+
+	// Workers write each result at its game's index, so results come back in
+	// the same order as games regardless of which worker finishes first
+	results := make([]gameResult, len(games))
+	started := make([]bool, len(games))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = processGame(ctx, taskSink, config, games[i])
+			}
+		}()
 	}
 
-	log.Printf("Processing %d games", len(games))
+feed:
+	for i := range games {
+		select {
+		case jobs <- i:
+			started[i] = true
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
 
-	for _, game := range games {
-		log.Printf("Processing game %d: %s", game.ID, game.StartTime)
+	if err := ctx.Err(); err != nil {
+		for i, game := range games {
+			if !started[i] {
+				results[i] = gameResult{Game: game, Status: notification.GameStatusFailed, Reason: err.Error(), Err: err}
+			}
+		}
+		return results, fmt.Errorf("processing cancelled: %w", err)
+	}
+
+	return results, nil
+}
 
-		if err := createCloudTask(ctx, client, config, game); err != nil {
-			log.Printf("Failed to create task for game %d: %v", game.ID, err)
+// processGame creates the tracking task, and the reminder task if enabled, for
+// a single game inside its own span; the span's context is carried into the
+// tasks' headers. With history enabled, a game unchanged since it was last
+// scheduled is skipped, and a changed game's earlier tasks are cancelled once
+// the tasks replacing them have been created.
+func processGame(ctx context.Context, taskSink sink.TaskSink, config *Config, game Game) gameResult {
+	ctx, span := tracing.Tracer().Start(ctx, "processGame", trace.WithAttributes(
+		attribute.Int("game.id", game.ID),
+		attribute.String("game.start_time", game.StartTime),
+	))
+	defer span.End()
+
+	slog.Info("Processing game", "game_id", game.ID, "start_time", game.StartTime)
+
+	if config.History == nil {
+		result, _ := scheduleGame(ctx, taskSink, config, game)
+		tracing.RecordError(span, result.Err)
+		return result
+	}
+
+	decision := history.Record{GameID: game.ID, StartTime: game.StartTime, PayloadHash: decisionHash(config, game), Sink: sinkTarget(config), RunID: config.RunID}
+	previous, found, err := config.History.Latest(decision.Sink, game.ID)
+	if err != nil {
+		slog.Warn("Failed to read scheduling history", "game_id", game.ID, "error", err)
+	}
+
+	if found && previous.Status == history.StatusScheduled && previous.PayloadHash == decision.PayloadHash && !config.Force {
+		slog.Info("Skipping unchanged game", "game_id", game.ID, "scheduled_by", previous.RunID)
+		decision.Status = history.StatusUnchanged
+		decision.TaskName, decision.ReminderTask, decision.ScheduleTime = previous.TaskName, previous.ReminderTask, previous.ScheduleTime
+		recordDecision(config, decision)
+		return gameResult{Game: game, Status: notification.GameStatusUnchanged, Reason: fmt.Sprintf(config.Messages.UnchangedSince, previous.RunID)}
+	}
+
+	result, tasks := scheduleGame(ctx, taskSink, config, game)
+	tracing.RecordError(span, result.Err)
+	if found {
+		tasks = replacePreviousTasks(ctx, taskSink, previous, tasks, result.Err == nil)
+		if previous.StartTime != game.StartTime {
+			result.PreviousStartTime = previous.StartTime
+		}
+	}
+
+	decision.Status = history.StatusScheduled
+	if result.Err != nil {
+		decision.Status, decision.Error = history.StatusFailed, result.Err.Error()
+	}
+	decision.TaskName, decision.ScheduleTime = tasks[0].Name, tasks[0].ScheduleTime
+	decision.ReminderTask = tasks[1].Name
+	recordDecision(config, decision)
+	return result
+}
+
+// scheduleGame creates a game's tracking task and, if enabled, its reminder,
+// returning the tasks created in that order; a task not created is empty
+func scheduleGame(ctx context.Context, taskSink sink.TaskSink, config *Config, game Game) (gameResult, [2]sink.Task) {
+	var tasks [2]sink.Task
+
+	task, err := createGameTask(ctx, taskSink, config, game)
+	if err != nil {
+		slog.Error("Failed to create task", "game_id", game.ID, "error", err)
+		return gameResult{Game: game, Status: notification.GameStatusFailed, Reason: err.Error(), Err: err}, tasks
+	}
+	tasks[0] = task
+
+	if config.ReminderURL != "" {
+		reminder, err := createReminderTask(ctx, taskSink, config, game)
+		if err != nil {
+			slog.Error("Failed to create reminder", "game_id", game.ID, "error", err)
+			err = fmt.Errorf("tracking task created, but %w", err)
+			return gameResult{Game: game, Status: notification.GameStatusFailed, Reason: err.Error(), Err: err}, tasks
+		}
+		tasks[1] = reminder
+	}
+
+	return gameResult{Game: game, Status: notification.GameStatusScheduled}, tasks
+}
+
+// decisionHash fingerprints what scheduling a game creates: the tracking
+// task's target and payload, and the reminder settings. A game whose hash
+// matches its last scheduled record is unchanged.
+func decisionHash(config *Config, game Game) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\n%s\n%s\n", gameTaskURL(config), config.ReminderURL, config.ReminderLead)
+	if startTime, err := time.Parse(time.RFC3339, game.StartTime); err == nil {
+		payload, _ := json.Marshal(newTaskPayload(config, game, startTime))
+		hash.Write(payload)
+	} else {
+		hash.Write([]byte(game.StartTime))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// replacePreviousTasks cancels the tasks a game was last scheduled with that
+// the new tasks replace, so a changed game is not tracked twice, and returns
+// the game's live tasks. After a failed attempt only tasks that were actually
+// replaced are cancelled; the rest are kept and carried into the returned
+// tasks, so the game is never left without tasks. A task with the same name
+// as its replacement is the same task and is left alone. Tasks that already
+// ran or were removed, and sinks that cannot cancel, are logged and otherwise
+// ignored.
+func replacePreviousTasks(ctx context.Context, taskSink sink.TaskSink, previous history.Record, tasks [2]sink.Task, succeeded bool) [2]sink.Task {
+	previousTasks := [2]sink.Task{{Name: previous.TaskName, ScheduleTime: previous.ScheduleTime}, {Name: previous.ReminderTask}}
+	for i, old := range previousTasks {
+		// Sinks may report a name relative to the queue or as a full path
+		if old.Name == "" || path.Base(old.Name) == path.Base(tasks[i].Name) {
+			continue
+		}
+		if tasks[i].Name == "" && !succeeded {
+			tasks[i] = old
+			continue
+		}
+
+		switch err := taskSink.Cancel(ctx, old.Name); {
+		case err == nil:
+			slog.Info("Cancelled task for changed game", "game_id", previous.GameID, "task", old.Name, "scheduled_by", previous.RunID)
+		case errors.Is(err, sink.ErrNotFound):
+			slog.Debug("Previous task already gone", "game_id", previous.GameID, "task", old.Name)
+		default:
+			slog.Warn("Failed to cancel previous task; the game may be tracked twice", "game_id", previous.GameID, "task", old.Name, "error", err)
+		}
+	}
+	return tasks
+}
+
+// recordDecision adds a decision to the history, logging rather than failing on errors
+func recordDecision(config *Config, decision history.Record) {
+	if err := config.History.Add(decision); err != nil {
+		slog.Warn("Failed to record scheduling decision", "game_id", decision.GameID, "error", err)
+	}
+}
+
+// skippedGames returns a skipped result for every game in all that is not in kept,
+// with the reason in the catalog's language
+func skippedGames(all, kept []Game, messages *notification.Messages) []gameResult {
+	keptIDs := make(map[int]bool)
+	for _, game := range kept {
+		keptIDs[game.ID] = true
+	}
+
+	var results []gameResult
+	for _, game := range all {
+		if keptIDs[game.ID] {
 			continue
 		}
+		reason := messages.GameStarted
+		if _, err := time.Parse(time.RFC3339, game.StartTime); err != nil {
+			reason = fmt.Sprintf(messages.InvalidStartTime, game.StartTime)
+		}
+		results = append(results, gameResult{Game: game, Status: notification.GameStatusSkipped, Reason: reason})
 	}
 
-	return nil
+	return results
+}
+
+// toNotificationGameInfo converts a game result to the notification package's game representation,
+// with full team names in the catalog's language
+func toNotificationGameInfo(result gameResult, messages *notification.Messages) notification.GameInfo {
+	game := result.Game
+	return notification.GameInfo{
+		ID:            strconv.Itoa(game.ID),
+		GameDate:      game.GameDate,
+		StartTime:     game.StartTime,
+		HomeTeam:      game.HomeTeam.Abbrev,
+		AwayTeam:      game.AwayTeam.Abbrev,
+		HomeTeamID:    game.HomeTeam.ID,
+		AwayTeamID:    game.AwayTeam.ID,
+		HomeTeamName:  messages.TeamName(game.HomeTeam.PlaceName, game.HomeTeam.CommonName, game.HomeTeam.PlaceNameWithPreposition),
+		AwayTeamName:  messages.TeamName(game.AwayTeam.PlaceName, game.AwayTeam.CommonName, game.AwayTeam.PlaceNameWithPreposition),
+		VenueTimezone: game.VenueTimezone,
+		Status:        result.Status,
+		Reason:        result.Reason,
+	}
+}
+
+// newNotifier builds the run's notifier: the catch-all Discord webhook receives
+// every game, while each team webhook only receives that team's games
+func newNotifier(sender notification.Sender, config *Config) notification.Notifier {
+	notifierOptions := notification.SenderNotifierOptions{Messages: config.Messages, Location: config.TimeZone}
+	notifiers := notification.MultiNotifier{
+		notification.Subscribe(notification.NewSenderNotifierWithOptions(sender, notifierOptions), config.DiscordEvents...),
+	}
+
+	if len(config.TeamWebhooks) > 0 {
+		routes := make(map[string]notification.Notifier)
+		for code, webhookURL := range config.TeamWebhooks {
+			teamSender := notification.NewDiscordSenderWithOptions(webhookURL, notification.DiscordOptions{
+				MentionRoleID: config.TeamRoles[code],
+				Messages:      config.Messages,
+				Location:      config.TimeZone,
+				HTTPClient:    discordClient,
+			})
+			routes[code] = notification.Subscribe(notification.NewSenderNotifierWithOptions(teamSender, notifierOptions), config.DiscordEvents...)
+		}
+		slog.Info("Discord team routing enabled", "teams", len(routes))
+		notifiers = append(notifiers, notification.NewTeamRouter(routes))
+	}
+
+	return notifiers
+}
+
+// notify delivers a notification event, logging rather than failing on errors
+func notify(ctx context.Context, notifier notification.Notifier, event notification.Event) {
+	if err := notifier.Notify(ctx, event); err != nil {
+		runMetrics.NotificationFailures.WithLabelValues(string(event.Kind())).Inc()
+		slog.Warn("Failed to send notification", "event", event.Kind(), "error", err)
+	}
+}
+
+// exportMetrics records the end of the run and writes the metrics textfile, if configured
+func exportMetrics(config *Config, success bool) {
+	runMetrics.FinishRun(success)
+	if config.MetricsTextfile == "" {
+		return
+	}
+	if err := runMetrics.WriteTextfile(config.MetricsTextfile); err != nil {
+		slog.Warn("Failed to export metrics", "error", err)
+	}
+}
+
+// endRun ends the run's span, recording err if the run aborted, and flushes pending spans
+func endRun(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	tracing.RecordError(span, err)
+	span.End()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := runTracing.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+}
+
+// abortRun sends a run aborted notification describing why the run stopped, then exits
+// with the code err carries
+func abortRun(ctx context.Context, config *Config, notifier notification.Notifier, err error) {
+	code := runExitCode(config.FailOn, err, 0, 0)
+	// Still notify and flush traces if the run was aborted by cancellation
+	ctx = context.WithoutCancel(ctx)
+	notify(ctx, notifier, notification.RunAborted{Err: err})
+	exportMetrics(config, false)
+	endRun(ctx, err)
+	fatal(code, "Run aborted", "error", err, "exit_code", code)
 }
 
-// main is the entry point of the application
+// main runs the subcommand named by the first argument
 func main() {
-	// Parse command-line flags
-	config := parseFlags()
+	cmd, args, ok := resolveCommand(os.Args[1:])
+	if !ok {
+		if len(os.Args) > 1 && !isHelpArg(os.Args[1]) && os.Args[1] != "help" {
+			fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", os.Args[1])
+		}
+		printUsage(os.Stderr)
+		if len(os.Args) > 1 && (isHelpArg(os.Args[1]) || os.Args[1] == "help") {
+			os.Exit(ExitOK)
+		}
+		os.Exit(ExitConfigError)
+	}
+	cmd.Run(args)
+}
 
-	log.Printf("Starting NHL Game Tracker Scheduler")
-	log.Printf("Configuration: Date=%s, Teams=%v, TestMode=%t, AllTeams=%t, Today=%t, Production=%t",
-		config.Date, config.Teams, config.TestMode, config.AllTeams, config.Today, config.Production)
+// runSchedule implements the schedule command: it fetches the slate, creates
+// the games' tasks and reports the run
+func runSchedule(args []string) {
+	config := parseScheduleFlags(args)
+
+	slog.Info("Starting NHL Game Tracker Scheduler")
+	slog.Info("Configuration",
+		"date", config.Date, "tz", config.TimeZone.String(), "teams", config.Teams, "test_mode", config.TestMode,
+		"all_teams", config.AllTeams, "today", config.Today, "production", config.Production)
+
+	// Cancel in-flight work on Ctrl-C or when systemd/docker stops the run
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Export the run's spans to an OTLP collector, if configured
+	provider, err := tracing.Setup(ctx, tracing.Options{
+		Endpoint:    config.OTLPEndpoint,
+		Insecure:    config.OTLPInsecure,
+		ServiceName: "gameTaskEmulator",
+		RunID:       config.RunID,
+	})
+	if err != nil {
+		fatal(ExitConfigError, "Failed to set up tracing", "error", err)
+	}
+	runTracing = provider
+
+	ctx, span := tracing.Tracer().Start(ctx, "run", trace.WithAttributes(
+		attribute.String("run.id", config.RunID),
+		attribute.String("game.date", config.Date),
+		attribute.Bool("run.test_mode", config.TestMode),
+	))
+	if runTracing.Enabled() {
+		// Tag every later log record with the trace so logs and spans can be joined
+		slog.SetDefault(slog.Default().With("trace_id", span.SpanContext().TraceID().String()))
+		slog.Info("Tracing enabled")
+	}
 
-	ctx := context.Background()
+	// Serve metrics for the duration of the run
+	if config.MetricsAddr != "" {
+		shutdown, err := runMetrics.Serve(config.MetricsAddr)
+		if err != nil {
+			fatal(ExitConfigError, "Failed to start metrics server", "error", err)
+		}
+		defer shutdown(ctx)
+		slog.Info("Serving metrics", "addr", config.MetricsAddr, "path", "/metrics")
+	}
 
 	// Initialize notification sender (dependency injection)
-	// The main function only knows about the Sender interface, not the concrete implementation
-	var notifier notification.Sender = notification.NewDiscordSender(config.DiscordWebhookURL)
-	if notifier.IsEnabled() {
-		log.Printf("Discord notifications enabled")
+	// The main function only knows about the Notifier interface, not the concrete implementation
+	sender := notification.NewDiscordSenderWithOptions(config.DiscordWebhookURL, notification.DiscordOptions{
+		Messages:   config.Messages,
+		Location:   config.TimeZone,
+		HTTPClient: discordClient,
+	})
+	if sender.IsEnabled() {
+		slog.Info("Discord notifications enabled")
 	} else {
-		log.Printf("Discord notifications disabled (no webhook URL configured)")
+		slog.Info("Discord notifications disabled (no webhook URL configured)")
 	}
+	notifier := newNotifier(sender, config)
 
-	// Connect to Cloud Tasks service (emulator or production)
-	client, conn, err := connectToTasksService(ctx, config)
+	notify(ctx, notifier, notification.RunStarted{Date: config.Date, TestMode: config.TestMode})
+
+	taskSink, closeSink, err := newTaskSink(ctx, config)
 	if err != nil {
-		log.Fatalf("Failed to connect to tasks service: %v", err)
+		abortRun(ctx, config, notifier, fmt.Errorf("Failed to connect to tasks service: %v", err))
 	}
-	defer conn.Close()
+	defer closeSink()
 
 	var games []Game
+	var skipped []gameResult
 
 	if config.TestMode {
 		gameID := 2024030411
 		if config.Shootout {
 			gameID = 2024030412
 		}
-		log.Printf("Running in test mode with predefined game ID: %d", gameID)
+		slog.Info("Running in test mode with predefined game ID", "game_id", gameID)
 		games = []Game{createTestGame(config.Shootout)}
 	} else {
 		// Fetch games from NHL API
-		fetchedGames, err := fetchGamesForDate(config.Date)
+		fetchedGames, err := fetchGamesForDate(ctx, config.Date)
 		if err != nil {
-			log.Fatalf("Failed to fetch games: %v", err)
+			abortRun(ctx, config, notifier, withExitCode(ExitNHLUnavailable, fmt.Errorf("Failed to fetch games: %v", err)))
 		}
 
 		// Filter games based on team selection
@@ -547,31 +1650,84 @@ func main() {
 
 		// If today flag is set, filter to only upcoming games
 		if config.Today {
-			games = filterUpcomingGames(games)
+			upcoming := filterUpcomingGames(games)
+			skipped = skippedGames(games, upcoming, config.Messages)
+			games = upcoming
+		}
+	}
+
+	// Record decisions, and skip unchanged games, unless this is a dry run
+	if config.HistoryPath != "" && config.Sink != SinkMemory {
+		config.History, err = history.Open(config.HistoryPath)
+		if err != nil {
+			abortRun(ctx, config, notifier, fmt.Errorf("Failed to open scheduling history: %v", err))
 		}
 	}
 
 	// Process games and create tasks
-	if err := processGames(ctx, client, config, games); err != nil {
-		log.Fatalf("Failed to process games: %v", err)
-	}
-
-	log.Printf("Successfully processed %d games", len(games))
-
-	// Send summary notification after all games have been processed
-	if notifier.IsEnabled() {
-		var gameInfos []notification.GameInfo
-		for _, game := range games {
-			gameInfos = append(gameInfos, notification.GameInfo{
-				ID:        strconv.Itoa(game.ID),
-				GameDate:  game.GameDate,
-				StartTime: game.StartTime,
-				HomeTeam:  game.HomeTeam.Abbrev,
-				AwayTeam:  game.AwayTeam.Abbrev,
-			})
+	results, err := processGames(ctx, taskSink, config, games)
+	if config.History != nil {
+		// Release the database now, so a resident local sink does not hold it
+		config.History.Close()
+		config.History = nil
+	}
+	if err != nil {
+		abortRun(ctx, config, notifier, fmt.Errorf("Failed to process games: %v", err))
+	}
+	if config.Sink == SinkMemory {
+		logPendingTasks(ctx, taskSink)
+	}
+
+	failed, unchanged := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case notification.GameStatusFailed:
+			failed++
+		case notification.GameStatusUnchanged:
+			unchanged++
 		}
-		if err := notifier.SendScheduleSummary(gameInfos); err != nil {
-			log.Printf("Warning: Failed to send schedule summary notification: %v", err)
+	}
+	// Unchanged games were not attempted, so they count toward neither success nor failure
+	attempted := len(results) - unchanged
+	slog.Info("Processed games",
+		"count", len(results)+len(skipped), "scheduled", attempted-failed, "skipped", len(skipped), "unchanged", unchanged, "failed", failed)
+
+	// Send per-game events, then the summary once all games have been processed
+	var gameInfos []notification.GameInfo
+	for _, result := range append(results, skipped...) {
+		info := toNotificationGameInfo(result, config.Messages)
+		if result.PreviousStartTime != "" {
+			notify(ctx, notifier, notification.ScheduleChanged{Game: info, PreviousStartTime: result.PreviousStartTime})
+		}
+		switch result.Status {
+		case notification.GameStatusScheduled:
+			notify(ctx, notifier, notification.GameScheduled{Game: info})
+		case notification.GameStatusSkipped:
+			notify(ctx, notifier, notification.GameSkipped{Game: info})
+		case notification.GameStatusFailed:
+			notify(ctx, notifier, notification.TaskFailed{Game: info, Err: result.Err})
 		}
+		gameInfos = append(gameInfos, info)
+	}
+	notify(ctx, notifier, notification.RunCompleted{Games: gameInfos})
+
+	exportMetrics(config, true)
+
+	var runErr error
+	if failed > 0 {
+		runErr = fmt.Errorf("%d of %d games failed to schedule", failed, attempted)
+	}
+	endRun(ctx, runErr)
+
+	if config.Sink == SinkLocal {
+		// The local sink delivers tasks itself, so stay resident until interrupted
+		slog.Info("Staying resident to deliver tasks; press Ctrl-C to stop")
+		<-ctx.Done()
+		closeSink()
+		slog.Info("Stopped local task delivery; pending tasks are kept", "state", config.LocalStatePath)
+	}
+
+	if code := runExitCode(config.FailOn, nil, attempted, failed); code != ExitOK {
+		fatal(code, "Run completed with failures", "failed", failed, "processed", attempted, "fail_on", config.FailOn, "exit_code", code)
 	}
 }
@@ -0,0 +1,719 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/emulator"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/history"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/notification"
+	"github.com/CrashTheCrease/backend/gameTaskEmulator/internal/sink"
+	"github.com/alicebob/miniredis/v2"
+	"google.golang.org/grpc"
+)
+
+// newTestConfig returns a configuration for scheduling against a test sink.
+func newTestConfig() *Config {
+	messages, _ := notification.MessagesFor("")
+	return &Config{
+		LocalMode:   true,
+		Concurrency: 4,
+		RunID:       "test-run",
+		Messages:    messages,
+	}
+}
+
+// newTestGames returns n games starting an hour apart, tomorrow.
+func newTestGames(n int) []Game {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Hour)
+	games := make([]Game, n)
+	for i := range games {
+		game := createTestGame(false)
+		game.ID = 2024020001 + i
+		game.StartTime = start.Add(time.Duration(i) * time.Hour).UTC().Format(time.RFC3339)
+		games[i] = game
+	}
+	return games
+}
+
+// failingSink fails to schedule tasks whose body mentions one of the game IDs.
+type failingSink struct {
+	sink.TaskSink
+	failIDs []int
+}
+
+func (f *failingSink) Schedule(ctx context.Context, task sink.Task) (sink.Task, error) {
+	for _, id := range f.failIDs {
+		if bytes.Contains(task.Body, []byte(`"id":"`+strconv.Itoa(id)+`"`)) {
+			return sink.Task{}, errors.New("sink unavailable")
+		}
+	}
+	return f.TaskSink.Schedule(ctx, task)
+}
+
+func TestProcessGamesSchedulesEveryGame(t *testing.T) {
+	memory := sink.NewMemory()
+	games := newTestGames(10)
+
+	results, err := processGames(context.Background(), memory, newTestConfig(), games)
+	if err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+
+	if len(results) != len(games) {
+		t.Fatalf("processGames() returned %d results, want %d", len(results), len(games))
+	}
+	for i, result := range results {
+		if result.Game.ID != games[i].ID {
+			t.Errorf("results[%d] is game %d, want %d (results must keep game order)", i, result.Game.ID, games[i].ID)
+		}
+		if result.Status != notification.GameStatusScheduled {
+			t.Errorf("results[%d].Status = %v, want scheduled", i, result.Status)
+		}
+	}
+
+	tasks, _ := memory.List(context.Background())
+	if len(tasks) != len(games) {
+		t.Fatalf("sink holds %d tasks, want %d", len(tasks), len(games))
+	}
+
+	task := tasks[0]
+	start, _ := time.Parse(time.RFC3339, games[0].StartTime)
+	if !task.ScheduleTime.Equal(start.Add(-5 * time.Minute)) {
+		t.Errorf("ScheduleTime = %s, want 5 minutes before %s", task.ScheduleTime, start)
+	}
+	if task.URL != "http://host.docker.internal:8080" {
+		t.Errorf("URL = %q, want the local host", task.URL)
+	}
+	if task.Headers[RunIDHeader] != "test-run" {
+		t.Errorf("%s header = %q, want the run ID", RunIDHeader, task.Headers[RunIDHeader])
+	}
+
+	var payload TaskPayload
+	if err := json.Unmarshal(task.Body, &payload); err != nil {
+		t.Fatalf("task body is not a TaskPayload: %v", err)
+	}
+	if payload.Game.ID != strconv.Itoa(games[0].ID) || !payload.ShouldNotify {
+		t.Errorf("payload = %+v, want game %d with ShouldNotify", payload, games[0].ID)
+	}
+}
+
+func TestProcessGamesReportsFailuresInOrder(t *testing.T) {
+	games := newTestGames(5)
+	failing := &failingSink{TaskSink: sink.NewMemory(), failIDs: []int{games[1].ID, games[3].ID}}
+
+	results, err := processGames(context.Background(), failing, newTestConfig(), games)
+	if err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+
+	for i, result := range results {
+		want := notification.GameStatusScheduled
+		if i == 1 || i == 3 {
+			want = notification.GameStatusFailed
+		}
+		if result.Status != want {
+			t.Errorf("results[%d].Status = %v, want %v", i, result.Status, want)
+		}
+		if want == notification.GameStatusFailed && result.Err == nil {
+			t.Errorf("results[%d].Err = nil, want the sink error", i)
+		}
+	}
+}
+
+func TestProcessGamesSchedulesReminders(t *testing.T) {
+	memory := sink.NewMemory()
+	config := newTestConfig()
+	config.ReminderURL = "http://relay.example.com/remind"
+	config.ReminderLead = 30 * time.Minute
+
+	if _, err := processGames(context.Background(), memory, config, newTestGames(2)); err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+
+	tasks, _ := memory.List(context.Background())
+	reminders := 0
+	for _, task := range tasks {
+		if task.URL == config.ReminderURL {
+			reminders++
+		}
+	}
+	if len(tasks) != 4 || reminders != 2 {
+		t.Errorf("sink holds %d tasks with %d reminders, want 4 with 2", len(tasks), reminders)
+	}
+}
+
+func TestReminderScheduleTime(t *testing.T) {
+	start := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		lead time.Duration
+		want time.Time
+	}{
+		{30 * time.Minute, time.Date(2025, 1, 15, 18, 30, 0, 0, time.UTC)},
+		{90 * time.Minute, time.Date(2025, 1, 15, 17, 30, 0, 0, time.UTC)},
+		{24 * time.Hour, time.Date(2025, 1, 14, 19, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := reminderScheduleTime(start, tt.lead); !got.Equal(tt.want) {
+			t.Errorf("reminderScheduleTime(%s) = %s, want %s", tt.lead, got, tt.want)
+		}
+	}
+}
+
+func TestCreateReminderTask(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name      string
+		startTime string
+		lead      time.Duration
+		wantTask  bool
+		wantErr   bool
+	}{
+		{"upcoming", now.Add(2 * time.Hour).UTC().Format(time.RFC3339), 30 * time.Minute, true, false},
+		{"reminder time already past", now.Add(20 * time.Minute).UTC().Format(time.RFC3339), 30 * time.Minute, false, false},
+		{"game already started", now.Add(-time.Hour).UTC().Format(time.RFC3339), 30 * time.Minute, false, false},
+		{"invalid start time", "not a time", 30 * time.Minute, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			memory := sink.NewMemory()
+			config := newTestConfig()
+			config.ReminderURL = "http://relay.example.com/remind"
+			config.ReminderLead = tt.lead
+			game := newTestGames(1)[0]
+			game.StartTime = tt.startTime
+
+			task, err := createReminderTask(context.Background(), memory, config, game)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("createReminderTask() error = %v, want error %v", err, tt.wantErr)
+			}
+			tasks, _ := memory.List(context.Background())
+			if !tt.wantTask {
+				if task.Name != "" || len(tasks) != 0 {
+					t.Errorf("createReminderTask() = %q with %d queued tasks, want no reminder", task.Name, len(tasks))
+				}
+				return
+			}
+
+			if len(tasks) != 1 || tasks[0].URL != config.ReminderURL {
+				t.Fatalf("sink holds %+v, want one reminder to the relay", tasks)
+			}
+			start, _ := time.Parse(time.RFC3339, tt.startTime)
+			if want := start.Add(-tt.lead); !tasks[0].ScheduleTime.Equal(want) {
+				t.Errorf("reminder scheduled at %s, want %s", tasks[0].ScheduleTime, want)
+			}
+			var payload ReminderPayload
+			if err := json.Unmarshal(tasks[0].Body, &payload); err != nil {
+				t.Fatalf("reminder body is not a ReminderPayload: %v", err)
+			}
+			if payload.Type != ReminderPayloadType || payload.LeadMinutes != 30 || payload.Message != "DAL @ BOS starts in 30 minutes" {
+				t.Errorf("payload = %+v, want a 30 minute pregame reminder", payload)
+			}
+			if tasks[0].Attributes["type"] != ReminderPayloadType {
+				t.Errorf("attributes = %v, want the reminder type", tasks[0].Attributes)
+			}
+		})
+	}
+}
+
+func TestProcessGamesStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	games := newTestGames(3)
+	results, err := processGames(ctx, sink.NewMemory(), newTestConfig(), games)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("processGames() error = %v, want context.Canceled", err)
+	}
+	if len(results) != len(games) {
+		t.Fatalf("processGames() returned %d results, want %d", len(results), len(games))
+	}
+	for i, result := range results {
+		if result.Status != notification.GameStatusFailed {
+			t.Errorf("results[%d].Status = %v, want failed", i, result.Status)
+		}
+	}
+}
+
+func TestProcessGamesWithNoGames(t *testing.T) {
+	results, err := processGames(context.Background(), sink.NewMemory(), newTestConfig(), nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("processGames(nil) = %v, %v, want no results", results, err)
+	}
+}
+
+// inFlightSink records the most Schedule calls it saw running at once.
+type inFlightSink struct {
+	sink.TaskSink
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func (s *inFlightSink) Schedule(ctx context.Context, task sink.Task) (sink.Task, error) {
+	s.mu.Lock()
+	s.inFlight++
+	s.max = max(s.max, s.inFlight)
+	s.mu.Unlock()
+
+	// Hold the call long enough for every free worker to pick up a game
+	time.Sleep(20 * time.Millisecond)
+
+	s.mu.Lock()
+	s.inFlight--
+	s.mu.Unlock()
+	return s.TaskSink.Schedule(ctx, task)
+}
+
+func TestProcessGamesBoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name        string
+		concurrency int
+		games       int
+		want        int
+	}{
+		{"more games than workers", 3, 12, 3},
+		{"one worker", 1, 4, 1},
+		{"more workers than games", 8, 2, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newTestConfig()
+			config.Concurrency = tt.concurrency
+			tracking := &inFlightSink{TaskSink: sink.NewMemory()}
+
+			if _, err := processGames(context.Background(), tracking, config, newTestGames(tt.games)); err != nil {
+				t.Fatalf("processGames() returned error: %v", err)
+			}
+			if tracking.max != tt.want {
+				t.Errorf("at most %d games were scheduled at once, want %d", tracking.max, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimitedSinkPacesCalls(t *testing.T) {
+	config := newTestConfig()
+	config.Concurrency = 2
+	config.RateLimit = 20
+	limited := limitSink(sink.NewMemory(), config)
+
+	// A burst of -concurrency calls goes through at once; the rest wait 50ms each
+	const calls = 6
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < calls; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := limited.Schedule(context.Background(), sink.Task{Name: strconv.Itoa(i)}); err != nil {
+				t.Errorf("Schedule() returned error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	want := time.Duration(calls-config.Concurrency) * time.Second / time.Duration(config.RateLimit)
+	if elapsed := time.Since(start); elapsed < want-10*time.Millisecond {
+		t.Errorf("%d calls took %s, want at least %s at %v per second", calls, elapsed, want, config.RateLimit)
+	}
+
+	// A call waiting for a token gives up with its context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limited.Schedule(ctx, sink.Task{Name: "late"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Schedule() with a cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestLimitSinkWithoutRateLimit(t *testing.T) {
+	memory := sink.NewMemory()
+	if got := limitSink(memory, newTestConfig()); got != sink.TaskSink(memory) {
+		t.Errorf("limitSink() with -rate-limit 0 = %T, want the sink unchanged", got)
+	}
+}
+
+func TestNewTaskSinkAppliesRateLimit(t *testing.T) {
+	config := newTestConfig()
+	config.LocalMode = false
+	config.Sink = SinkRedis
+	config.RedisAddr = miniredis.RunT(t).Addr()
+	config.RedisQueue = "default"
+	config.RateLimit = 5
+
+	taskSink, closeSink, err := newTaskSink(context.Background(), config)
+	if err != nil {
+		t.Fatalf("newTaskSink() returned error: %v", err)
+	}
+	defer closeSink()
+
+	if _, ok := taskSink.(*rateLimitedSink); !ok {
+		t.Errorf("newTaskSink(-sink redis -rate-limit 5) = %T, want it rate limited", taskSink)
+	}
+}
+
+func TestSkippedGamesUseCatalogReasons(t *testing.T) {
+	games := newTestGames(3)
+	games[1].StartTime = "not a time"
+
+	tests := []struct {
+		lang        string
+		started     string
+		invalidTime string
+	}{
+		{"en", "Game has already started", `Invalid start time "not a time"`},
+		{"fr", "Le match a déjà commencé", `Heure de début invalide "not a time"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			messages, _ := notification.MessagesFor(tt.lang)
+			results := skippedGames(games, games[2:], messages)
+			if len(results) != 2 {
+				t.Fatalf("skippedGames() returned %d results, want 2", len(results))
+			}
+			if results[0].Status != notification.GameStatusSkipped || results[0].Reason != tt.started {
+				t.Errorf("results[0] = %v %q, want skipped as %q", results[0].Status, results[0].Reason, tt.started)
+			}
+			if results[1].Reason != tt.invalidTime {
+				t.Errorf("results[1].Reason = %q, want %q", results[1].Reason, tt.invalidTime)
+			}
+		})
+	}
+}
+
+func TestProcessGamesSkipsUnchangedGames(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("history.Open() returned error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	memory := sink.NewMemory()
+	games := newTestGames(3)
+	run := func(runID string, force bool, games []Game) []gameResult {
+		t.Helper()
+		config := newTestConfig()
+		config.RunID, config.Force, config.History = runID, force, store
+		results, err := processGames(ctx, memory, config, games)
+		if err != nil {
+			t.Fatalf("processGames() returned error: %v", err)
+		}
+		return results
+	}
+
+	run("run-1", false, games)
+	first, _ := memory.List(ctx)
+
+	// The second run leaves unchanged games alone and replaces the moved game's task
+	moved := append([]Game(nil), games...)
+	previousStart := moved[1].StartTime
+	start, _ := time.Parse(time.RFC3339, previousStart)
+	moved[1].StartTime = start.Add(time.Hour).Format(time.RFC3339)
+
+	results := run("run-2", false, moved)
+	for _, i := range []int{0, 2} {
+		if results[i].Status != notification.GameStatusUnchanged || results[i].Reason != "Unchanged since run run-1" {
+			t.Errorf("results[%d] = %v %q, want unchanged since run-1", i, results[i].Status, results[i].Reason)
+		}
+	}
+	if results[1].Status != notification.GameStatusScheduled || results[1].PreviousStartTime != previousStart {
+		t.Errorf("results[1] = %+v, want rescheduled with the previous start time", results[1])
+	}
+
+	tasks, _ := memory.List(ctx)
+	if len(tasks) != 3 {
+		t.Fatalf("sink holds %d tasks after rescheduling, want 3", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.Name == first[1].Name {
+			t.Errorf("moved game's original task %s was not cancelled", task.Name)
+		}
+	}
+
+	// -force reschedules every game without duplicating tasks
+	for i, result := range run("run-3", true, moved) {
+		if result.Status != notification.GameStatusScheduled {
+			t.Errorf("forced results[%d].Status = %v, want scheduled", i, result.Status)
+		}
+	}
+	if tasks, _ := memory.List(ctx); len(tasks) != 3 {
+		t.Errorf("sink holds %d tasks after a forced run, want 3", len(tasks))
+	}
+
+	records, _ := store.Find(history.Query{RunID: "run-2"})
+	statuses := map[int]string{}
+	for _, record := range records {
+		statuses[record.GameID] = record.Status
+	}
+	if statuses[games[0].ID] != history.StatusUnchanged || statuses[games[1].ID] != history.StatusScheduled {
+		t.Errorf("run-2 recorded %v, want game 0 unchanged and game 1 scheduled", statuses)
+	}
+}
+
+func TestProcessGamesKeepsPreviousTasksWhenReschedulingFails(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("history.Open() returned error: %v", err)
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	memory := sink.NewMemory()
+	config := newTestConfig()
+	config.History = store
+	games := newTestGames(1)
+
+	if _, err := processGames(ctx, memory, config, games); err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+	original, _ := memory.List(ctx)
+
+	// Move the game, then fail to schedule its replacement task
+	start, _ := time.Parse(time.RFC3339, games[0].StartTime)
+	games[0].StartTime = start.Add(time.Hour).Format(time.RFC3339)
+	config.RunID = "test-run-2"
+	results, _ := processGames(ctx, &failingSink{TaskSink: memory, failIDs: []int{games[0].ID}}, config, games)
+	if results[0].Status != notification.GameStatusFailed {
+		t.Fatalf("results[0].Status = %v, want failed", results[0].Status)
+	}
+	if tasks, _ := memory.List(ctx); len(tasks) != 1 || tasks[0].Name != original[0].Name {
+		t.Fatalf("sink holds %+v after a failed reschedule, want the original task", tasks)
+	}
+	if latest, _, _ := store.Latest(sinkTarget(config), games[0].ID); latest.TaskName != original[0].Name {
+		t.Errorf("failed decision records task %q, want the still-live %q", latest.TaskName, original[0].Name)
+	}
+
+	// The next successful attempt replaces it
+	config.RunID = "test-run-3"
+	if _, err := processGames(ctx, memory, config, games); err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+	if tasks, _ := memory.List(ctx); len(tasks) != 1 || tasks[0].Name == original[0].Name {
+		t.Errorf("sink holds %+v, want only the replacement task", tasks)
+	}
+}
+
+func TestTaskNameIdentifiesTaskContent(t *testing.T) {
+	at := time.Date(2025, 1, 15, 18, 55, 0, 0, time.UTC)
+	game := map[string]string{"gameId": "2024020712"}
+	reminder := map[string]string{"gameId": "2024020712", "type": ReminderPayloadType}
+	name := taskName(game, "http://tracker", []byte("{}"), at)
+
+	if !strings.HasPrefix(name, "game-2024020712-") {
+		t.Errorf("taskName() = %q, want the game ID in the name", name)
+	}
+	if again := taskName(game, "http://tracker", []byte("{}"), at); again != name {
+		t.Errorf("taskName() = %q then %q, want the same name for the same task", name, again)
+	}
+	for _, other := range []string{
+		taskName(game, "http://tracker", []byte("{}"), at.Add(time.Hour)),
+		taskName(game, "http://tracker", []byte(`{"x":1}`), at),
+		taskName(game, "http://other", []byte("{}"), at),
+		taskName(reminder, "http://tracker", []byte("{}"), at),
+	} {
+		if other == name {
+			t.Errorf("taskName() = %q for a different task", other)
+		}
+	}
+}
+
+// --- Integration ---
+
+// startEmulator serves a Cloud Tasks emulator on a loopback port until the
+// test ends and returns its address.
+func startEmulator(t *testing.T) string {
+	t.Helper()
+
+	server, err := emulator.New(emulator.Options{Tick: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("emulator.New() returned error: %v", err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		cancel()
+		<-done
+	})
+	return listener.Addr().String()
+}
+
+func TestScheduleAndDispatchThroughEmulator(t *testing.T) {
+	bodies := make(chan []byte, 10)
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer target.Close()
+
+	config := newTestConfig()
+	config.LocalMode = false
+	config.HostURL = target.URL
+	config.EmulatorHost = startEmulator(t)
+	config.ProjectID, config.Location, config.QueueName = "localproject", "us-south1", "gameschedule"
+	config.DialTimeout = 5 * time.Second
+	config.RPCAttempts = 1
+
+	ctx := context.Background()
+	taskSink, closeSink, err := newTaskSink(ctx, config)
+	if err != nil {
+		t.Fatalf("newTaskSink() returned error: %v", err)
+	}
+	defer closeSink()
+
+	// Games starting five minutes from now are due at once
+	games := newTestGames(2)
+	for i := range games {
+		games[i].StartTime = time.Now().Add(5 * time.Minute).UTC().Format(time.RFC3339)
+	}
+	results, err := processGames(ctx, taskSink, config, games)
+	if err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+	for i, result := range results {
+		if result.Status != notification.GameStatusScheduled {
+			t.Errorf("results[%d].Status = %v, want scheduled", i, result.Status)
+		}
+	}
+
+	seen := make(map[int]bool)
+	for range games {
+		select {
+		case body := <-bodies:
+			var payload TaskPayload
+			if err := json.Unmarshal(body, &payload); err != nil {
+				t.Fatalf("dispatched body is not a TaskPayload: %v", err)
+			}
+			id, _ := strconv.Atoi(payload.Game.ID)
+			seen[id] = true
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the emulator to dispatch tasks")
+		}
+	}
+	for _, game := range games {
+		if !seen[game.ID] {
+			t.Errorf("game %d was not dispatched", game.ID)
+		}
+	}
+}
+
+func TestSchedulePublishesToPubSub(t *testing.T) {
+	server := pstest.NewServer()
+	defer server.Close()
+
+	config := newTestConfig()
+	config.LocalMode = false
+	config.Sink = SinkPubSub
+	config.PubSubEmulator = server.Addr
+	config.PubSubTopic = "gameschedule"
+	config.ProjectID = "localproject"
+	config.DialTimeout = 5 * time.Second
+
+	ctx := context.Background()
+	taskSink, closeSink, err := newTaskSink(ctx, config)
+	if err != nil {
+		t.Fatalf("newTaskSink() returned error: %v", err)
+	}
+
+	games := newTestGames(2)
+	if _, err := processGames(ctx, taskSink, config, games); err != nil {
+		t.Fatalf("processGames() returned error: %v", err)
+	}
+	if err := closeSink(); err != nil {
+		t.Fatalf("closing the sink returned error: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != len(games) {
+		t.Fatalf("topic received %d messages, want %d", len(messages), len(games))
+	}
+	for _, message := range messages {
+		var payload TaskPayload
+		if err := json.Unmarshal(message.Data, &payload); err != nil {
+			t.Fatalf("message data is not a TaskPayload: %v", err)
+		}
+		if message.Attributes["gameId"] != payload.Game.ID {
+			t.Errorf("gameId attribute = %q, want %q", message.Attributes["gameId"], payload.Game.ID)
+		}
+		if payload.ExecutionEnd == nil || message.Attributes["executionEnd"] != *payload.ExecutionEnd {
+			t.Errorf("executionEnd attribute = %q, want the payload's execution end", message.Attributes["executionEnd"])
+		}
+		if message.Attributes["runId"] != config.RunID || message.Attributes["deliverAt"] == "" {
+			t.Errorf("attributes = %v, want runId and deliverAt", message.Attributes)
+		}
+	}
+}
+
+func TestScheduleEnqueuesRedisJobsOnce(t *testing.T) {
+	config := newTestConfig()
+	config.LocalMode = false
+	config.Sink = SinkRedis
+	config.RedisAddr = miniredis.RunT(t).Addr()
+	config.RedisQueue = "default"
+
+	ctx := context.Background()
+	taskSink, closeSink, err := newTaskSink(ctx, config)
+	if err != nil {
+		t.Fatalf("newTaskSink() returned error: %v", err)
+	}
+	defer closeSink()
+
+	// A second run over the same games finds their jobs already enqueued
+	games := newTestGames(3)
+	for run := 1; run <= 2; run++ {
+		results, err := processGames(ctx, taskSink, config, games)
+		if err != nil {
+			t.Fatalf("run %d: processGames() returned error: %v", run, err)
+		}
+		for i, result := range results {
+			if result.Status != notification.GameStatusScheduled {
+				t.Errorf("run %d: results[%d].Status = %v, want scheduled", run, i, result.Status)
+			}
+		}
+	}
+
+	tasks, err := taskSink.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if len(tasks) != len(games) {
+		t.Fatalf("queue holds %d jobs, want one per game (%d)", len(tasks), len(games))
+	}
+	for i, task := range tasks {
+		if want := "game-" + strconv.Itoa(games[i].ID) + "-"; !strings.HasPrefix(task.Name, want) {
+			t.Errorf("tasks[%d].Name = %q, want prefix %q", i, task.Name, want)
+		}
+	}
+}
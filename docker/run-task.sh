@@ -1,11 +1,26 @@
 #!/bin/bash
 # Task execution script for cron scheduled runs
 # This script is called by cron and runs the gameTaskEmulator with configured flags
+#
+# gameTaskEmulator exit codes:
+#   0 - all games scheduled (or nothing to schedule)
+#   2 - partial failure: some games failed to schedule (not retried; the failed games usually fail again)
+#   3 - total failure: no game could be scheduled (retried)
+#   4 - configuration error (not retried)
+#   5 - NHL API unavailable (retried)
 
 set -e
 
+# Retry policy for transient failures (exit codes 3 and 5)
+RETRY_ATTEMPTS="${RETRY_ATTEMPTS:-3}"
+RETRY_DELAY="${RETRY_DELAY:-300}"
+
+log() {
+    echo "[$(date '+%Y-%m-%d %H:%M:%S')] $1"
+}
+
 # Log execution start
-echo "[$(date '+%Y-%m-%d %H:%M:%S')] Starting gameTaskEmulator scheduled run"
+log "Starting gameTaskEmulator scheduled run"
 
 # Build command with flags from environment variables
 CMD="/app/gameTaskEmulator"
@@ -21,19 +36,45 @@ if [ -n "${TEAM_CODE}" ]; then
     ARGS="${ARGS} -teams ${TEAM_CODE}"
 fi
 
-# Log the command being executed
-echo "[$(date '+%Y-%m-%d %H:%M:%S')] Executing: ${CMD} ${ARGS}"
-
-# Execute the command
-${CMD} ${ARGS}
+ATTEMPT=1
+while true; do
+    # Log the command being executed
+    log "Executing (attempt ${ATTEMPT}/${RETRY_ATTEMPTS}): ${CMD} ${ARGS}"
 
-EXIT_CODE=$?
-
-# Log completion
-if [ $EXIT_CODE -eq 0 ]; then
-    echo "[$(date '+%Y-%m-%d %H:%M:%S')] gameTaskEmulator completed successfully"
-else
-    echo "[$(date '+%Y-%m-%d %H:%M:%S')] gameTaskEmulator failed with exit code ${EXIT_CODE}"
-fi
+    # Execute the command, capturing its exit code without tripping set -e
+    EXIT_CODE=0
+    ${CMD} ${ARGS} || EXIT_CODE=$?
 
-exit $EXIT_CODE
+    case $EXIT_CODE in
+        0)
+            log "gameTaskEmulator completed successfully"
+            exit 0
+            ;;
+        2)
+            log "ALERT: gameTaskEmulator partially failed (exit code 2) - some games were not scheduled"
+            exit $EXIT_CODE
+            ;;
+        4)
+            log "ALERT: gameTaskEmulator configuration error (exit code 4) - check TEAM_CODE and ADDITIONAL_FLAGS"
+            exit $EXIT_CODE
+            ;;
+        3|5)
+            if [ "$EXIT_CODE" -eq 5 ]; then
+                REASON="NHL API unavailable"
+            else
+                REASON="no games could be scheduled"
+            fi
+            if [ "$ATTEMPT" -ge "$RETRY_ATTEMPTS" ]; then
+                log "ALERT: gameTaskEmulator failed with exit code ${EXIT_CODE} (${REASON}) after ${ATTEMPT} attempts"
+                exit $EXIT_CODE
+            fi
+            log "gameTaskEmulator failed with exit code ${EXIT_CODE} (${REASON}), retrying in ${RETRY_DELAY}s"
+            sleep "$RETRY_DELAY"
+            ATTEMPT=$((ATTEMPT + 1))
+            ;;
+        *)
+            log "ALERT: gameTaskEmulator failed with exit code ${EXIT_CODE}"
+            exit $EXIT_CODE
+            ;;
+    esac
+done
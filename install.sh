@@ -216,6 +216,10 @@ EnvironmentFile=-${ENV_FILE}
 Environment="TEAM_CODE="
 Environment="ADDITIONAL_FLAGS=-today"
 
+# Persistent data (the scheduling history) lives in /var/lib/gameTaskEmulator;
+# systemd passes it to run.sh as STATE_DIRECTORY
+StateDirectory=gameTaskEmulator
+
 # Execute the run script with team code if specified
 ExecStart=/bin/bash -c '${INSTALL_DIR}/run.sh \${ADDITIONAL_FLAGS} \${TEAM_CODE:+-teams} \${TEAM_CODE}'
 
@@ -0,0 +1,204 @@
+package emulator
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Retry defaults, matching Cloud Tasks when a queue has no RetryConfig.
+const (
+	defaultMaxAttempts = 100
+	defaultMinBackoff  = 100 * time.Millisecond
+	defaultMaxBackoff  = time.Hour
+)
+
+// Run dispatches tasks as they come due until ctx is cancelled, then waits
+// for dispatches in progress to stop. Tasks in paused queues stay pending.
+func (s *Server) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.options.Tick)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		for _, task := range s.due(time.Now()) {
+			wg.Add(1)
+			go func(task *taskspb.Task) {
+				defer wg.Done()
+				s.dispatch(ctx, task)
+			}(task)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// due marks the tasks in running queues whose schedule time has passed as
+// in flight and returns copies of them.
+func (s *Server) due(now time.Time) []*taskspb.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tasks []*taskspb.Task
+	for queueName, queue := range s.queues {
+		if queue.State != taskspb.Queue_RUNNING {
+			continue
+		}
+		for name, task := range s.tasks[queueName] {
+			if s.inFlight[name] || task.ScheduleTime.AsTime().After(now) {
+				continue
+			}
+			s.inFlight[name] = true
+			tasks = append(tasks, proto.Clone(task).(*taskspb.Task))
+		}
+	}
+	return tasks
+}
+
+// dispatch makes one attempt at a task, then deletes it on success or
+// reschedules it with backoff according to its queue's retry config. The
+// task must have been marked in flight.
+func (s *Server) dispatch(ctx context.Context, task *taskspb.Task) {
+	started := time.Now()
+	statusCode, err := s.send(ctx, task.GetHttpRequest())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.inFlight, task.Name)
+
+	current, ok := s.tasks[queueOf(task.Name)][task.Name]
+	if !ok {
+		// Deleted or purged while in flight
+		return
+	}
+	if err != nil && ctx.Err() != nil {
+		// Stopping: leave the task untouched so the next start retries it
+		return
+	}
+
+	attempt := &taskspb.Attempt{
+		ScheduleTime: current.ScheduleTime,
+		DispatchTime: timestamppb.New(started),
+	}
+	current.DispatchCount++
+	if current.FirstAttempt == nil {
+		current.FirstAttempt = attempt
+	}
+	current.LastAttempt = attempt
+	if statusCode != 0 {
+		current.ResponseCount++
+		attempt.ResponseTime = timestamppb.Now()
+		attempt.ResponseStatus = &status.Status{Code: int32(httpToRPCCode(statusCode)), Message: http.StatusText(statusCode)}
+	}
+
+	retry := s.queues[queueOf(task.Name)].GetRetryConfig()
+	switch {
+	case err == nil:
+		slog.Info("Dispatched task", "task", task.Name, "url", task.GetHttpRequest().Url, "attempt", current.DispatchCount)
+		delete(s.tasks[queueOf(task.Name)], task.Name)
+	case exhausted(retry, current):
+		slog.Error("Giving up on task", "task", task.Name, "url", task.GetHttpRequest().Url, "attempts", current.DispatchCount, "error", err)
+		delete(s.tasks[queueOf(task.Name)], task.Name)
+	default:
+		wait := backoff(retry, current.DispatchCount)
+		slog.Warn("Task dispatch failed, retrying", "task", task.Name, "attempt", current.DispatchCount, "retry_in", wait, "error", err)
+		current.ScheduleTime = timestamppb.New(time.Now().Add(wait))
+	}
+
+	if err := s.saveLocked(); err != nil {
+		slog.Warn("Failed to persist emulator state", "error", err)
+	}
+}
+
+// send makes one HTTP request and returns the response status, or 0 if no
+// response was received; any non-2xx response is an error.
+func (s *Server) send(ctx context.Context, target *taskspb.HttpRequest) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, target.HttpMethod.String(), target.Url, bytes.NewReader(target.Body))
+	if err != nil {
+		return 0, err
+	}
+	for key, value := range target.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.options.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("target returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// exhausted reports whether a task has used all its attempts, or its
+// MaxRetryDuration has passed since the first attempt. A negative MaxAttempts
+// means unlimited, as in Cloud Tasks.
+func exhausted(retry *taskspb.RetryConfig, task *taskspb.Task) bool {
+	maxAttempts := retry.GetMaxAttempts()
+	if maxAttempts == 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	if maxAttempts > 0 && task.DispatchCount >= maxAttempts {
+		return true
+	}
+	limit := retry.GetMaxRetryDuration().AsDuration()
+	return limit > 0 && time.Since(task.FirstAttempt.GetDispatchTime().AsTime()) >= limit
+}
+
+// backoff returns the wait after the given number of failed attempts: the
+// minimum backoff doubled per attempt, capped at the maximum.
+func backoff(retry *taskspb.RetryConfig, attempts int32) time.Duration {
+	minBackoff, maxBackoff := defaultMinBackoff, defaultMaxBackoff
+	if d := retry.GetMinBackoff(); d != nil {
+		minBackoff = d.AsDuration()
+	}
+	if d := retry.GetMaxBackoff(); d != nil {
+		maxBackoff = d.AsDuration()
+	}
+
+	wait := minBackoff
+	for i := int32(1); i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+// httpToRPCCode maps an HTTP response status to the RPC code Cloud Tasks
+// records for the attempt.
+func httpToRPCCode(statusCode int) code.Code {
+	switch {
+	case statusCode >= 200 && statusCode <= 299:
+		return code.Code_OK
+	case statusCode == http.StatusNotFound:
+		return code.Code_NOT_FOUND
+	case statusCode == http.StatusTooManyRequests:
+		return code.Code_RESOURCE_EXHAUSTED
+	case statusCode == http.StatusServiceUnavailable:
+		return code.Code_UNAVAILABLE
+	case statusCode >= 500:
+		return code.Code_INTERNAL
+	default:
+		return code.Code_FAILED_PRECONDITION
+	}
+}
@@ -0,0 +1,443 @@
+// Package emulator implements the subset of the Cloud Tasks API this project
+// uses, so local development and integration tests don't need a third-party
+// emulator. It stores queues and HTTP tasks in memory or in a state file,
+// dispatches tasks at their schedule time and exposes an HTTP endpoint for
+// inspecting queue contents.
+package emulator
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Options configures a Server.
+type Options struct {
+	StatePath  string        // File queues and tasks are persisted to; empty keeps them in memory only
+	Tick       time.Duration // How often due tasks are dispatched; 0 uses 100ms
+	HTTPClient *http.Client  // Client that dispatches tasks; nil uses one with a 30 second timeout
+}
+
+// Server is an in-process Cloud Tasks emulator. It implements
+// taskspb.CloudTasksServer; RPCs outside the supported subset return
+// Unimplemented.
+type Server struct {
+	taskspb.UnimplementedCloudTasksServer
+
+	options Options
+
+	mu       sync.Mutex
+	queues   map[string]*taskspb.Queue
+	tasks    map[string]map[string]*taskspb.Task // queue name -> task name -> task
+	inFlight map[string]bool
+}
+
+// New creates an emulator, loading any state persisted at options.StatePath.
+// Call Run to start dispatching tasks.
+func New(options Options) (*Server, error) {
+	if options.Tick <= 0 {
+		options.Tick = 100 * time.Millisecond
+	}
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	s := &Server{
+		options:  options,
+		queues:   make(map[string]*taskspb.Queue),
+		tasks:    make(map[string]map[string]*taskspb.Task),
+		inFlight: make(map[string]bool),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Register registers the emulator's Cloud Tasks service on a gRPC server.
+func (s *Server) Register(server *grpc.Server) {
+	taskspb.RegisterCloudTasksServer(server, s)
+}
+
+// --- Queues ---
+
+// CreateQueue creates a running queue.
+func (s *Server) CreateQueue(ctx context.Context, req *taskspb.CreateQueueRequest) (*taskspb.Queue, error) {
+	name := req.GetQueue().GetName()
+	if !strings.HasPrefix(name, req.GetParent()+"/queues/") || req.GetParent() == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "queue name %q must be in parent %q", name, req.GetParent())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.queues[name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "queue %s already exists", name)
+	}
+	queue := proto.Clone(req.Queue).(*taskspb.Queue)
+	queue.State = taskspb.Queue_RUNNING
+	s.queues[name] = queue
+	s.tasks[name] = make(map[string]*taskspb.Task)
+	return proto.Clone(queue).(*taskspb.Queue), s.saveLocked()
+}
+
+// GetQueue returns a queue.
+func (s *Server) GetQueue(ctx context.Context, req *taskspb.GetQueueRequest) (*taskspb.Queue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, err := s.queueLocked(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(queue).(*taskspb.Queue), nil
+}
+
+// ListQueues lists the queues in a location, ordered by name.
+func (s *Server) ListQueues(ctx context.Context, req *taskspb.ListQueuesRequest) (*taskspb.ListQueuesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for name := range s.queues {
+		if strings.HasPrefix(name, req.GetParent()+"/queues/") {
+			names = append(names, name)
+		}
+	}
+
+	page, next, err := paginate(names, req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+	resp := &taskspb.ListQueuesResponse{NextPageToken: next}
+	for _, name := range page {
+		resp.Queues = append(resp.Queues, proto.Clone(s.queues[name]).(*taskspb.Queue))
+	}
+	return resp, nil
+}
+
+// UpdateQueue changes the fields of a queue named in the update mask, or
+// all of its rate limits, retry config and logging config if the mask is
+// empty. As in Cloud Tasks, a queue that doesn't exist is created.
+func (s *Server) UpdateQueue(ctx context.Context, req *taskspb.UpdateQueueRequest) (*taskspb.Queue, error) {
+	update := req.GetQueue()
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{"rate_limits", "retry_config", "stackdriver_logging_config"}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, ok := s.queues[update.GetName()]
+	if !ok {
+		if !strings.Contains(update.GetName(), "/queues/") {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid queue name %q", update.GetName())
+		}
+		queue = &taskspb.Queue{Name: update.Name, State: taskspb.Queue_RUNNING}
+	}
+	updated := proto.Clone(queue).(*taskspb.Queue)
+	for _, path := range paths {
+		if err := applyQueueField(updated, update, path); err != nil {
+			return nil, err
+		}
+	}
+
+	s.queues[updated.Name] = updated
+	if !ok {
+		s.tasks[updated.Name] = make(map[string]*taskspb.Task)
+	}
+	return proto.Clone(updated).(*taskspb.Queue), s.saveLocked()
+}
+
+// applyQueueField copies the field at path from update to queue.
+func applyQueueField(queue, update *taskspb.Queue, path string) error {
+	group, field, _ := strings.Cut(path, ".")
+	switch group {
+	case "rate_limits":
+		if queue.RateLimits == nil {
+			queue.RateLimits = &taskspb.RateLimits{}
+		}
+		from := update.GetRateLimits()
+		switch field {
+		case "":
+			queue.RateLimits = proto.Clone(from).(*taskspb.RateLimits)
+		case "max_dispatches_per_second":
+			queue.RateLimits.MaxDispatchesPerSecond = from.GetMaxDispatchesPerSecond()
+		case "max_burst_size":
+			queue.RateLimits.MaxBurstSize = from.GetMaxBurstSize()
+		case "max_concurrent_dispatches":
+			queue.RateLimits.MaxConcurrentDispatches = from.GetMaxConcurrentDispatches()
+		default:
+			return status.Errorf(codes.InvalidArgument, "unsupported update mask path %q", path)
+		}
+	case "retry_config":
+		if queue.RetryConfig == nil {
+			queue.RetryConfig = &taskspb.RetryConfig{}
+		}
+		from := update.GetRetryConfig()
+		switch field {
+		case "":
+			queue.RetryConfig = proto.Clone(from).(*taskspb.RetryConfig)
+		case "max_attempts":
+			queue.RetryConfig.MaxAttempts = from.GetMaxAttempts()
+		case "max_retry_duration":
+			queue.RetryConfig.MaxRetryDuration = from.GetMaxRetryDuration()
+		case "min_backoff":
+			queue.RetryConfig.MinBackoff = from.GetMinBackoff()
+		case "max_backoff":
+			queue.RetryConfig.MaxBackoff = from.GetMaxBackoff()
+		case "max_doublings":
+			queue.RetryConfig.MaxDoublings = from.GetMaxDoublings()
+		default:
+			return status.Errorf(codes.InvalidArgument, "unsupported update mask path %q", path)
+		}
+	case "stackdriver_logging_config":
+		if field != "" && field != "sampling_ratio" {
+			return status.Errorf(codes.InvalidArgument, "unsupported update mask path %q", path)
+		}
+		queue.StackdriverLoggingConfig = proto.Clone(update.GetStackdriverLoggingConfig()).(*taskspb.StackdriverLoggingConfig)
+	default:
+		return status.Errorf(codes.InvalidArgument, "unsupported update mask path %q", path)
+	}
+	return nil
+}
+
+// PauseQueue stops dispatching the queue's tasks.
+func (s *Server) PauseQueue(ctx context.Context, req *taskspb.PauseQueueRequest) (*taskspb.Queue, error) {
+	return s.setQueueState(req.GetName(), taskspb.Queue_PAUSED)
+}
+
+// ResumeQueue resumes dispatching the queue's tasks.
+func (s *Server) ResumeQueue(ctx context.Context, req *taskspb.ResumeQueueRequest) (*taskspb.Queue, error) {
+	return s.setQueueState(req.GetName(), taskspb.Queue_RUNNING)
+}
+
+// PurgeQueue deletes every task in the queue.
+func (s *Server) PurgeQueue(ctx context.Context, req *taskspb.PurgeQueueRequest) (*taskspb.Queue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, err := s.queueLocked(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	s.tasks[queue.Name] = make(map[string]*taskspb.Task)
+	queue.PurgeTime = timestamppb.Now()
+	return proto.Clone(queue).(*taskspb.Queue), s.saveLocked()
+}
+
+func (s *Server) setQueueState(name string, state taskspb.Queue_State) (*taskspb.Queue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue, err := s.queueLocked(name)
+	if err != nil {
+		return nil, err
+	}
+	queue.State = state
+	return proto.Clone(queue).(*taskspb.Queue), s.saveLocked()
+}
+
+// queueLocked returns the named queue. The caller must hold s.mu.
+func (s *Server) queueLocked(name string) (*taskspb.Queue, error) {
+	queue, ok := s.queues[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "queue %s not found", name)
+	}
+	return queue, nil
+}
+
+// --- Tasks ---
+
+// CreateTask adds an HTTP task to a queue. Tasks without a name get a random
+// one; tasks without a schedule time are due immediately.
+func (s *Server) CreateTask(ctx context.Context, req *taskspb.CreateTaskRequest) (*taskspb.Task, error) {
+	if req.GetTask().GetHttpRequest().GetUrl() == "" {
+		return nil, status.Error(codes.InvalidArgument, "only HTTP tasks with a URL are supported")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.queueLocked(req.GetParent()); err != nil {
+		return nil, err
+	}
+
+	task := proto.Clone(req.Task).(*taskspb.Task)
+	if task.Name == "" {
+		task.Name = req.Parent + "/tasks/" + strconv.FormatUint(rand.Uint64(), 10)
+	} else if !strings.HasPrefix(task.Name, req.Parent+"/tasks/") {
+		return nil, status.Errorf(codes.InvalidArgument, "task name %q must be in queue %q", task.Name, req.Parent)
+	}
+	if _, ok := s.tasks[req.Parent][task.Name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "task %s already exists", task.Name)
+	}
+
+	task.CreateTime = timestamppb.Now()
+	if task.ScheduleTime == nil {
+		task.ScheduleTime = task.CreateTime
+	}
+	if task.GetHttpRequest().HttpMethod == taskspb.HttpMethod_HTTP_METHOD_UNSPECIFIED {
+		task.GetHttpRequest().HttpMethod = taskspb.HttpMethod_POST
+	}
+	task.View = taskspb.Task_FULL
+	s.tasks[req.Parent][task.Name] = task
+	if err := s.saveLocked(); err != nil {
+		delete(s.tasks[req.Parent], task.Name)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return viewOf(task, req.GetResponseView()), nil
+}
+
+// GetTask returns a task.
+func (s *Server) GetTask(ctx context.Context, req *taskspb.GetTaskRequest) (*taskspb.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.taskLocked(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return viewOf(task, req.GetResponseView()), nil
+}
+
+// ListTasks lists a queue's tasks, ordered by name.
+func (s *Server) ListTasks(ctx context.Context, req *taskspb.ListTasksRequest) (*taskspb.ListTasksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.queueLocked(req.GetParent()); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range s.tasks[req.Parent] {
+		names = append(names, name)
+	}
+	page, next, err := paginate(names, req.GetPageSize(), req.GetPageToken())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &taskspb.ListTasksResponse{NextPageToken: next}
+	for _, name := range page {
+		resp.Tasks = append(resp.Tasks, viewOf(s.tasks[req.Parent][name], req.GetResponseView()))
+	}
+	return resp, nil
+}
+
+// DeleteTask removes a task.
+func (s *Server) DeleteTask(ctx context.Context, req *taskspb.DeleteTaskRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, err := s.taskLocked(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	delete(s.tasks[queueOf(task.Name)], task.Name)
+	return &emptypb.Empty{}, s.saveLocked()
+}
+
+// RunTask dispatches a task now, even if its queue is paused, and returns it
+// as it was before the attempt.
+func (s *Server) RunTask(ctx context.Context, req *taskspb.RunTaskRequest) (*taskspb.Task, error) {
+	s.mu.Lock()
+	task, err := s.taskLocked(req.GetName())
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	if s.inFlight[task.Name] {
+		s.mu.Unlock()
+		return nil, status.Errorf(codes.FailedPrecondition, "task %s is already being dispatched", task.Name)
+	}
+	s.inFlight[task.Name] = true
+	snapshot := proto.Clone(task).(*taskspb.Task)
+	s.mu.Unlock()
+
+	go s.dispatch(context.Background(), snapshot)
+	return viewOf(snapshot, req.GetResponseView()), nil
+}
+
+// taskLocked returns the named task. The caller must hold s.mu.
+func (s *Server) taskLocked(name string) (*taskspb.Task, error) {
+	task, ok := s.tasks[queueOf(name)][name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "task %s not found", name)
+	}
+	return task, nil
+}
+
+// queueOf returns the queue name of a task name.
+func queueOf(taskName string) string {
+	queue, _, _ := strings.Cut(taskName, "/tasks/")
+	return queue
+}
+
+// viewOf returns a copy of task for a response; the BASIC view omits the body
+// and headers, as in Cloud Tasks.
+func viewOf(task *taskspb.Task, view taskspb.Task_View) *taskspb.Task {
+	clone := proto.Clone(task).(*taskspb.Task)
+	if view != taskspb.Task_FULL {
+		clone.View = taskspb.Task_BASIC
+		if req := clone.GetHttpRequest(); req != nil {
+			req.Body = nil
+			req.Headers = nil
+		}
+	}
+	return clone
+}
+
+// paginate returns one page of sorted names and the token for the next page.
+// Page tokens are offsets; a page size of 0 means all remaining names.
+func paginate(names []string, pageSize int32, pageToken string) ([]string, string, error) {
+	sort.Strings(names)
+
+	start := 0
+	if pageToken != "" {
+		offset, err := strconv.Atoi(pageToken)
+		if err != nil || offset < 0 {
+			return nil, "", status.Errorf(codes.InvalidArgument, "invalid page token %q", pageToken)
+		}
+		start = min(offset, len(names))
+	}
+
+	end := len(names)
+	if pageSize > 0 && start+int(pageSize) < end {
+		end = start + int(pageSize)
+	}
+
+	next := ""
+	if end < len(names) {
+		next = strconv.Itoa(end)
+	}
+	return names[start:end], next, nil
+}
+
+// String describes the emulator's contents, for logs.
+func (s *Server) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, tasks := range s.tasks {
+		count += len(tasks)
+	}
+	return fmt.Sprintf("%d queues, %d tasks", len(s.queues), count)
+}
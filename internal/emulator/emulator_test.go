@@ -0,0 +1,472 @@
+package emulator
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	testLocation = "projects/proj/locations/us-south1"
+	testQueue    = testLocation + "/queues/gameschedule"
+)
+
+// targetServer records the bodies it receives and fails the first failures
+// of them with a 503.
+type targetServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	bodies   []string
+	received chan struct{}
+}
+
+func newTargetServer(t *testing.T, failures int) *targetServer {
+	t.Helper()
+
+	s := &targetServer{failures: failures, received: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.bodies = append(s.bodies, string(body))
+		fail := s.failures > 0
+		if fail {
+			s.failures--
+		}
+		s.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		s.received <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// waitForRequests waits until the target has received n requests.
+func (s *targetServer) waitForRequests(t *testing.T, n int) []string {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for request %d of %d", i+1, n)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.bodies...)
+}
+
+// newTestEmulator starts an emulator on an in-memory listener, dispatching
+// until the test ends, and returns it with a client connected to it.
+func newTestEmulator(t *testing.T, statePath string) (*Server, taskspb.CloudTasksClient) {
+	t.Helper()
+
+	server, err := New(Options{StatePath: statePath, Tick: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+	go grpcServer.Serve(listener)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		grpcServer.Stop()
+		cancel()
+		<-done
+	})
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial emulator: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return server, taskspb.NewCloudTasksClient(conn)
+}
+
+func createQueue(t *testing.T, client taskspb.CloudTasksClient, retry *taskspb.RetryConfig) {
+	t.Helper()
+
+	_, err := client.CreateQueue(context.Background(), &taskspb.CreateQueueRequest{
+		Parent: testLocation,
+		Queue:  &taskspb.Queue{Name: testQueue, RetryConfig: retry},
+	})
+	if err != nil {
+		t.Fatalf("CreateQueue() returned error: %v", err)
+	}
+}
+
+func createTask(t *testing.T, client taskspb.CloudTasksClient, name, url, body string, at time.Time) *taskspb.Task {
+	t.Helper()
+
+	task := &taskspb.Task{
+		MessageType: &taskspb.Task_HttpRequest{HttpRequest: &taskspb.HttpRequest{
+			Url:  url,
+			Body: []byte(body),
+		}},
+		ScheduleTime: timestamppb.New(at),
+	}
+	if name != "" {
+		task.Name = testQueue + "/tasks/" + name
+	}
+	created, err := client.CreateTask(context.Background(), &taskspb.CreateTaskRequest{Parent: testQueue, Task: task})
+	if err != nil {
+		t.Fatalf("CreateTask() returned error: %v", err)
+	}
+	return created
+}
+
+func listTasks(t *testing.T, client taskspb.CloudTasksClient) []*taskspb.Task {
+	t.Helper()
+
+	resp, err := client.ListTasks(context.Background(), &taskspb.ListTasksRequest{Parent: testQueue})
+	if err != nil {
+		t.Fatalf("ListTasks() returned error: %v", err)
+	}
+	return resp.Tasks
+}
+
+// waitForTasks waits until the queue holds n tasks.
+func waitForTasks(t *testing.T, client taskspb.CloudTasksClient, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if len(listTasks(t, client)) == n {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d tasks", n)
+}
+
+// --- Queues ---
+
+func TestQueueLifecycle(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+	ctx := context.Background()
+
+	createQueue(t, client, nil)
+	_, err := client.CreateQueue(ctx, &taskspb.CreateQueueRequest{Parent: testLocation, Queue: &taskspb.Queue{Name: testQueue}})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("second CreateQueue() error = %v, want AlreadyExists", err)
+	}
+
+	queue, err := client.GetQueue(ctx, &taskspb.GetQueueRequest{Name: testQueue})
+	if err != nil || queue.State != taskspb.Queue_RUNNING {
+		t.Errorf("GetQueue() = %v, %v, want a running queue", queue, err)
+	}
+	if _, err := client.GetQueue(ctx, &taskspb.GetQueueRequest{Name: testLocation + "/queues/missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("GetQueue(missing) error = %v, want NotFound", err)
+	}
+
+	queue, err = client.PauseQueue(ctx, &taskspb.PauseQueueRequest{Name: testQueue})
+	if err != nil || queue.State != taskspb.Queue_PAUSED {
+		t.Errorf("PauseQueue() = %v, %v, want a paused queue", queue, err)
+	}
+	queue, err = client.ResumeQueue(ctx, &taskspb.ResumeQueueRequest{Name: testQueue})
+	if err != nil || queue.State != taskspb.Queue_RUNNING {
+		t.Errorf("ResumeQueue() = %v, %v, want a running queue", queue, err)
+	}
+
+	resp, err := client.ListQueues(ctx, &taskspb.ListQueuesRequest{Parent: testLocation, PageSize: 1})
+	if err != nil || len(resp.Queues) != 1 || resp.NextPageToken != "" {
+		t.Errorf("ListQueues() = %v, %v, want the one queue", resp, err)
+	}
+}
+
+func TestCreateQueueRejectsNameOutsideParent(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+
+	_, err := client.CreateQueue(context.Background(), &taskspb.CreateQueueRequest{
+		Parent: testLocation,
+		Queue:  &taskspb.Queue{Name: "projects/other/locations/us-south1/queues/q"},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateQueue() error = %v, want InvalidArgument", err)
+	}
+}
+
+func TestUpdateQueueAppliesMaskedFields(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+	ctx := context.Background()
+
+	createQueue(t, client, &taskspb.RetryConfig{MaxAttempts: 5, MinBackoff: durationpb.New(time.Second)})
+	queue, err := client.UpdateQueue(ctx, &taskspb.UpdateQueueRequest{
+		Queue: &taskspb.Queue{
+			Name:        testQueue,
+			RateLimits:  &taskspb.RateLimits{MaxDispatchesPerSecond: 5, MaxConcurrentDispatches: 7},
+			RetryConfig: &taskspb.RetryConfig{MaxAttempts: 3, MinBackoff: durationpb.New(time.Minute)},
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"rate_limits.max_dispatches_per_second", "retry_config.max_attempts"}},
+	})
+	if err != nil {
+		t.Fatalf("UpdateQueue() returned error: %v", err)
+	}
+	if queue.GetRateLimits().GetMaxDispatchesPerSecond() != 5 || queue.GetRetryConfig().GetMaxAttempts() != 3 {
+		t.Errorf("UpdateQueue() = %v, want the masked fields changed", queue)
+	}
+	if queue.GetRateLimits().GetMaxConcurrentDispatches() != 0 || queue.GetRetryConfig().GetMinBackoff().AsDuration() != time.Second {
+		t.Errorf("UpdateQueue() = %v, want fields outside the mask kept", queue)
+	}
+
+	_, err = client.UpdateQueue(ctx, &taskspb.UpdateQueueRequest{
+		Queue:      &taskspb.Queue{Name: testQueue},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"state"}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("UpdateQueue(state) error = %v, want InvalidArgument", err)
+	}
+
+	// Updating a missing queue creates it, as in Cloud Tasks
+	created, err := client.UpdateQueue(ctx, &taskspb.UpdateQueueRequest{Queue: &taskspb.Queue{Name: testLocation + "/queues/other"}})
+	if err != nil || created.State != taskspb.Queue_RUNNING {
+		t.Errorf("UpdateQueue(missing) = %v, %v, want a new running queue", created, err)
+	}
+}
+
+// --- Tasks ---
+
+func TestTaskCRUD(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+	ctx := context.Background()
+	later := time.Now().Add(time.Hour)
+
+	if _, err := client.CreateTask(ctx, &taskspb.CreateTaskRequest{Parent: testQueue, Task: &taskspb.Task{
+		MessageType: &taskspb.Task_HttpRequest{HttpRequest: &taskspb.HttpRequest{Url: "http://example.com"}},
+	}}); status.Code(err) != codes.NotFound {
+		t.Errorf("CreateTask() before CreateQueue error = %v, want NotFound", err)
+	}
+
+	createQueue(t, client, nil)
+	named := createTask(t, client, "game-1", "http://example.com", `{"game":{}}`, later)
+	if named.GetHttpRequest().GetHttpMethod() != taskspb.HttpMethod_POST {
+		t.Errorf("HttpMethod = %s, want POST default", named.GetHttpRequest().GetHttpMethod())
+	}
+	if named.GetHttpRequest().GetBody() != nil {
+		t.Errorf("CreateTask() returned body %q in the BASIC view", named.GetHttpRequest().GetBody())
+	}
+	unnamed := createTask(t, client, "", "http://example.com", "", later)
+	if queueOf(unnamed.Name) != testQueue {
+		t.Errorf("generated name %q is not in the queue", unnamed.Name)
+	}
+
+	_, err := client.CreateTask(ctx, &taskspb.CreateTaskRequest{Parent: testQueue, Task: &taskspb.Task{
+		Name:        testQueue + "/tasks/game-1",
+		MessageType: &taskspb.Task_HttpRequest{HttpRequest: &taskspb.HttpRequest{Url: "http://example.com"}},
+	}})
+	if status.Code(err) != codes.AlreadyExists {
+		t.Errorf("duplicate CreateTask() error = %v, want AlreadyExists", err)
+	}
+
+	resp, err := client.ListTasks(ctx, &taskspb.ListTasksRequest{Parent: testQueue, PageSize: 1, ResponseView: taskspb.Task_FULL})
+	if err != nil || len(resp.Tasks) != 1 || resp.NextPageToken == "" {
+		t.Fatalf("ListTasks() first page = %v, %v, want one task and a next page", resp, err)
+	}
+	next, err := client.ListTasks(ctx, &taskspb.ListTasksRequest{Parent: testQueue, PageSize: 1, PageToken: resp.NextPageToken})
+	if err != nil || len(next.Tasks) != 1 || next.NextPageToken != "" {
+		t.Fatalf("ListTasks() second page = %v, %v, want the last task", next, err)
+	}
+	if resp.Tasks[0].Name == next.Tasks[0].Name {
+		t.Errorf("both pages returned %s", resp.Tasks[0].Name)
+	}
+
+	got, err := client.GetTask(ctx, &taskspb.GetTaskRequest{Name: named.Name, ResponseView: taskspb.Task_FULL})
+	if err != nil || string(got.GetHttpRequest().GetBody()) != `{"game":{}}` {
+		t.Errorf("GetTask(FULL) = %v, %v, want the body", got, err)
+	}
+
+	if _, err := client.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Name: named.Name}); err != nil {
+		t.Fatalf("DeleteTask() returned error: %v", err)
+	}
+	if _, err := client.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Name: named.Name}); status.Code(err) != codes.NotFound {
+		t.Errorf("second DeleteTask() error = %v, want NotFound", err)
+	}
+	if tasks := listTasks(t, client); len(tasks) != 1 {
+		t.Errorf("ListTasks() returned %d tasks after delete, want 1", len(tasks))
+	}
+
+	if _, err := client.PurgeQueue(ctx, &taskspb.PurgeQueueRequest{Name: testQueue}); err != nil {
+		t.Fatalf("PurgeQueue() returned error: %v", err)
+	}
+	if tasks := listTasks(t, client); len(tasks) != 0 {
+		t.Errorf("ListTasks() returned %d tasks after purge, want 0", len(tasks))
+	}
+}
+
+// --- Dispatch ---
+
+func TestDispatchesTaskAtScheduleTime(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+	target := newTargetServer(t, 0)
+	createQueue(t, client, nil)
+
+	at := time.Now().Add(100 * time.Millisecond)
+	createTask(t, client, "game-1", target.URL, `{"game":{}}`, at)
+
+	bodies := target.waitForRequests(t, 1)
+	if time.Now().Before(at) {
+		t.Errorf("task dispatched before its schedule time")
+	}
+	if bodies[0] != `{"game":{}}` {
+		t.Errorf("body = %q, want the task body", bodies[0])
+	}
+	waitForTasks(t, client, 0)
+}
+
+func TestDispatchRetriesWithQueueRetryConfig(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+	target := newTargetServer(t, 10)
+	createQueue(t, client, &taskspb.RetryConfig{
+		MaxAttempts: 3,
+		MinBackoff:  durationpb.New(time.Millisecond),
+		MaxBackoff:  durationpb.New(time.Millisecond),
+	})
+
+	createTask(t, client, "game-1", target.URL, "", time.Now())
+	target.waitForRequests(t, 3)
+	waitForTasks(t, client, 0)
+
+	select {
+	case <-target.received:
+		t.Error("task dispatched again after MaxAttempts")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestPausedQueueHoldsTasksUntilRunTask(t *testing.T) {
+	_, client := newTestEmulator(t, "")
+	ctx := context.Background()
+	target := newTargetServer(t, 0)
+	createQueue(t, client, nil)
+
+	if _, err := client.PauseQueue(ctx, &taskspb.PauseQueueRequest{Name: testQueue}); err != nil {
+		t.Fatalf("PauseQueue() returned error: %v", err)
+	}
+	task := createTask(t, client, "game-1", target.URL, "", time.Now())
+
+	select {
+	case <-target.received:
+		t.Fatal("paused queue dispatched a task")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if _, err := client.RunTask(ctx, &taskspb.RunTaskRequest{Name: task.Name}); err != nil {
+		t.Fatalf("RunTask() returned error: %v", err)
+	}
+	target.waitForRequests(t, 1)
+	waitForTasks(t, client, 0)
+}
+
+// --- State ---
+
+func TestStatePersistsAcrossRestarts(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "emulator.json")
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	server, client := newTestEmulator(t, statePath)
+	createQueue(t, client, nil)
+	createTask(t, client, "game-1", "http://example.com", `{"game":{}}`, at)
+	if _, err := client.PauseQueue(context.Background(), &taskspb.PauseQueueRequest{Name: testQueue}); err != nil {
+		t.Fatalf("PauseQueue() returned error: %v", err)
+	}
+	if got := server.String(); got != "1 queues, 1 tasks" {
+		t.Errorf("String() = %q, want 1 queue and 1 task", got)
+	}
+
+	restarted, err := New(Options{StatePath: statePath})
+	if err != nil {
+		t.Fatalf("New() after restart returned error: %v", err)
+	}
+	queue, err := restarted.GetQueue(context.Background(), &taskspb.GetQueueRequest{Name: testQueue})
+	if err != nil || queue.State != taskspb.Queue_PAUSED {
+		t.Errorf("restored queue = %v, %v, want it paused", queue, err)
+	}
+	task, err := restarted.GetTask(context.Background(), &taskspb.GetTaskRequest{Name: testQueue + "/tasks/game-1", ResponseView: taskspb.Task_FULL})
+	if err != nil {
+		t.Fatalf("restored GetTask() returned error: %v", err)
+	}
+	if !task.ScheduleTime.AsTime().Equal(at) || string(task.GetHttpRequest().GetBody()) != `{"game":{}}` {
+		t.Errorf("restored task = %v, want the original schedule time and body", task)
+	}
+}
+
+// --- Inspection ---
+
+func TestInspectHandler(t *testing.T) {
+	server, client := newTestEmulator(t, "")
+	createQueue(t, client, nil)
+	createTask(t, client, "game-1", "http://example.com", `{"game":{}}`, time.Now().Add(time.Hour))
+
+	inspect := httptest.NewServer(server.InspectHandler())
+	defer inspect.Close()
+
+	var queues []QueueInfo
+	getJSON(t, inspect.URL+"/queues", &queues)
+	if len(queues) != 1 || queues[0].Name != testQueue || queues[0].State != "RUNNING" || queues[0].Tasks != 1 {
+		t.Errorf("/queues = %+v, want the running queue with 1 task", queues)
+	}
+
+	var tasks []TaskInfo
+	getJSON(t, inspect.URL+"/tasks?queue="+testQueue, &tasks)
+	if len(tasks) != 1 || tasks[0].Body != `{"game":{}}` || tasks[0].Method != "POST" {
+		t.Errorf("/tasks = %+v, want the task with its body", tasks)
+	}
+
+	getJSON(t, inspect.URL+"/tasks?queue=other", &tasks)
+	if len(tasks) != 0 {
+		t.Errorf("/tasks?queue=other = %+v, want none", tasks)
+	}
+}
+
+func getJSON(t *testing.T, url string, v any) {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s returned error: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s returned status %d", url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s returned invalid JSON: %v", url, err)
+	}
+}
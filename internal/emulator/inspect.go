@@ -0,0 +1,104 @@
+package emulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+)
+
+// QueueInfo describes a queue on the inspection endpoint.
+type QueueInfo struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	Tasks int    `json:"tasks"`
+}
+
+// TaskInfo describes a task on the inspection endpoint. The body is shown as
+// text, since our task payloads are JSON.
+type TaskInfo struct {
+	Name          string            `json:"name"`
+	Method        string            `json:"method"`
+	URL           string            `json:"url"`
+	Headers       map[string]string `json:"headers,omitempty"`
+	Body          string            `json:"body,omitempty"`
+	ScheduleTime  time.Time         `json:"scheduleTime"`
+	DispatchCount int32             `json:"dispatchCount"`
+	LastStatus    string            `json:"lastStatus,omitempty"`
+	InFlight      bool              `json:"inFlight"`
+}
+
+// InspectHandler returns an HTTP handler for inspecting the emulator:
+//
+//	GET /queues             lists queues with their state and task counts
+//	GET /tasks?queue=NAME   lists tasks, optionally only those in one queue
+func (s *Server) InspectHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/queues", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, s.queueInfos())
+	})
+	mux.HandleFunc("/tasks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, r, s.taskInfos(r.URL.Query().Get("queue")))
+	})
+	return mux
+}
+
+func (s *Server) queueInfos() []QueueInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := []QueueInfo{}
+	for _, name := range sortedKeys(s.queues) {
+		infos = append(infos, QueueInfo{
+			Name:  name,
+			State: s.queues[name].State.String(),
+			Tasks: len(s.tasks[name]),
+		})
+	}
+	return infos
+}
+
+func (s *Server) taskInfos(queue string) []TaskInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	infos := []TaskInfo{}
+	for _, queueName := range sortedKeys(s.tasks) {
+		if queue != "" && queueName != queue {
+			continue
+		}
+		for _, name := range sortedKeys(s.tasks[queueName]) {
+			infos = append(infos, taskInfo(s.tasks[queueName][name], s.inFlight[name]))
+		}
+	}
+	return infos
+}
+
+func taskInfo(task *taskspb.Task, inFlight bool) TaskInfo {
+	info := TaskInfo{
+		Name:          task.Name,
+		Method:        task.GetHttpRequest().GetHttpMethod().String(),
+		URL:           task.GetHttpRequest().GetUrl(),
+		Headers:       task.GetHttpRequest().GetHeaders(),
+		Body:          string(task.GetHttpRequest().GetBody()),
+		ScheduleTime:  task.GetScheduleTime().AsTime(),
+		DispatchCount: task.DispatchCount,
+		InFlight:      inFlight,
+	}
+	if s := task.GetLastAttempt().GetResponseStatus(); s != nil {
+		info.LastStatus = s.Message
+	}
+	return info
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
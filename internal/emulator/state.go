@@ -0,0 +1,119 @@
+package emulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// emulatorState is the on-disk form of the queues and tasks. Each entry is
+// the protojson encoding of a Queue or Task.
+type emulatorState struct {
+	Queues []json.RawMessage `json:"queues"`
+	Tasks  []json.RawMessage `json:"tasks"`
+}
+
+// load reads the persisted queues and tasks, if any.
+func (s *Server) load() error {
+	if s.options.StatePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.options.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read emulator state: %w", err)
+	}
+
+	var state emulatorState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse emulator state %s: %w", s.options.StatePath, err)
+	}
+
+	for _, raw := range state.Queues {
+		queue := &taskspb.Queue{}
+		if err := protojson.Unmarshal(raw, queue); err != nil {
+			return fmt.Errorf("failed to parse emulator state %s: %w", s.options.StatePath, err)
+		}
+		s.queues[queue.Name] = queue
+		s.tasks[queue.Name] = make(map[string]*taskspb.Task)
+	}
+	for _, raw := range state.Tasks {
+		task := &taskspb.Task{}
+		if err := protojson.Unmarshal(raw, task); err != nil {
+			return fmt.Errorf("failed to parse emulator state %s: %w", s.options.StatePath, err)
+		}
+		tasks, ok := s.tasks[queueOf(task.Name)]
+		if !ok {
+			return fmt.Errorf("failed to parse emulator state %s: task %s has no queue", s.options.StatePath, task.Name)
+		}
+		tasks[task.Name] = task
+	}
+	return nil
+}
+
+// saveLocked atomically writes the queues and tasks to the state file. The
+// caller must hold s.mu.
+func (s *Server) saveLocked() error {
+	if s.options.StatePath == "" {
+		return nil
+	}
+
+	var state emulatorState
+	for _, name := range sortedKeys(s.queues) {
+		raw, err := protojson.Marshal(s.queues[name])
+		if err != nil {
+			return fmt.Errorf("failed to encode emulator state: %w", err)
+		}
+		state.Queues = append(state.Queues, raw)
+
+		for _, taskName := range sortedKeys(s.tasks[name]) {
+			raw, err := protojson.Marshal(s.tasks[name][taskName])
+			if err != nil {
+				return fmt.Errorf("failed to encode emulator state: %w", err)
+			}
+			state.Tasks = append(state.Tasks, raw)
+		}
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode emulator state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.options.StatePath), filepath.Base(s.options.StatePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write emulator state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write emulator state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write emulator state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.options.StatePath); err != nil {
+		return fmt.Errorf("failed to write emulator state: %w", err)
+	}
+	return nil
+}
+
+// sortedKeys returns a map's keys in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
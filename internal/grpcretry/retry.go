@@ -0,0 +1,158 @@
+// Package grpcretry classifies Cloud Tasks RPC errors by their gRPC status
+// code, retries the transient ones with exponential backoff and turns the
+// permanent ones into actionable messages.
+package grpcretry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Class is what a caller should do about an RPC error.
+type Class int
+
+const (
+	// Success means the RPC succeeded, or the resource it would have created
+	// already exists.
+	Success Class = iota
+	// Retry means the error is transient and the RPC may be retried.
+	Retry
+	// Fail means retrying will not help.
+	Fail
+)
+
+// String returns the class name, for logs.
+func (c Class) String() string {
+	switch c {
+	case Success:
+		return "success"
+	case Retry:
+		return "retry"
+	default:
+		return "fail"
+	}
+}
+
+// Classify maps an RPC error to a Class by its gRPC status code.
+func Classify(err error) Class {
+	switch status.Code(err) {
+	case codes.OK, codes.AlreadyExists:
+		return Success
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return Retry
+	default:
+		return Fail
+	}
+}
+
+// Policy controls how transient errors are retried.
+type Policy struct {
+	MaxAttempts    int           // Total attempts per RPC, including the first
+	InitialBackoff time.Duration // Wait before the first retry
+	MaxBackoff     time.Duration // Upper bound on the wait between retries
+	Multiplier     float64       // Backoff growth factor per retry
+}
+
+// DefaultPolicy returns the policy used for Cloud Tasks RPCs.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:    5,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// backoff returns the wait before retry number n (starting at 1), with up to
+// 20% jitter either way so parallel workers don't retry in lockstep.
+func (p Policy) backoff(n int) time.Duration {
+	d := float64(p.InitialBackoff)
+	for i := 1; i < n; i++ {
+		d *= p.Multiplier
+	}
+	if limit := float64(p.MaxBackoff); d > limit {
+		d = limit
+	}
+	return time.Duration(d * (0.8 + 0.4*rand.Float64()))
+}
+
+// Idempotent reports whether an RPC can be repeated without doing its work
+// twice. A transient error such as DeadlineExceeded does not say whether the
+// server acted, so only these RPCs are retried: creating a named task is safe
+// because a repeat fails with AlreadyExists, while creating an unnamed task or
+// forcing one to run is not.
+func Idempotent(req interface{}) bool {
+	switch r := req.(type) {
+	case *taskspb.CreateTaskRequest:
+		return r.GetTask().GetName() != ""
+	case *taskspb.RunTaskRequest:
+		return false
+	default:
+		return true
+	}
+}
+
+// UnaryClientInterceptor retries idempotent RPCs that fail with a transient
+// error, up to the policy's attempt limit, and annotates permanent errors with
+// a hint on how to fix them. The returned error keeps its gRPC status code.
+func UnaryClientInterceptor(policy Policy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		maxAttempts := policy.MaxAttempts
+		if !Idempotent(req) {
+			maxAttempts = 1
+		}
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if Classify(err) != Retry || attempt >= maxAttempts {
+				break
+			}
+
+			timer := time.NewTimer(policy.backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return fmt.Errorf("%s: gave up retrying after %d attempts: %w", method, attempt, err)
+			case <-timer.C:
+			}
+		}
+
+		switch {
+		case err == nil:
+			return nil
+		case Classify(err) == Retry && !Idempotent(req):
+			return fmt.Errorf("%s: not retried because it may already have taken effect: %w", method, err)
+		case Classify(err) == Retry:
+			return fmt.Errorf("%s: still failing after %d attempts: %w", method, maxAttempts, err)
+		default:
+			return Actionable(method, err)
+		}
+	}
+}
+
+// Actionable wraps err with a hint on how to fix it when its status code
+// points at a configuration problem rather than a transient failure.
+func Actionable(method string, err error) error {
+	var hint string
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		hint = "check -project, -location, -queue and the task's target URL"
+	case codes.PermissionDenied:
+		hint = "check that the credentials in GOOGLE_APPLICATION_CREDENTIALS have the Cloud Tasks Enqueuer role on the queue"
+	case codes.Unauthenticated:
+		hint = "check that GOOGLE_APPLICATION_CREDENTIALS points at a valid service account key"
+	case codes.NotFound:
+		hint = "check that the queue exists in the given -project and -location"
+	default:
+		return err
+	}
+	return fmt.Errorf("%s failed (%s): %w", method, hint, err)
+}
@@ -0,0 +1,295 @@
+package grpcretry
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeCloudTasksServer fails CreateTask and CreateQueue with the scripted
+// errors in order, then succeeds.
+type fakeCloudTasksServer struct {
+	taskspb.UnimplementedCloudTasksServer
+
+	mu       sync.Mutex
+	errs     []error
+	attempts int
+}
+
+func (s *fakeCloudTasksServer) next() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if len(s.errs) == 0 {
+		return nil
+	}
+	err := s.errs[0]
+	s.errs = s.errs[1:]
+	return err
+}
+
+func (s *fakeCloudTasksServer) CreateTask(ctx context.Context, req *taskspb.CreateTaskRequest) (*taskspb.Task, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return &taskspb.Task{Name: req.Parent + "/tasks/1"}, nil
+}
+
+func (s *fakeCloudTasksServer) RunTask(ctx context.Context, req *taskspb.RunTaskRequest) (*taskspb.Task, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return &taskspb.Task{Name: req.Name}, nil
+}
+
+func (s *fakeCloudTasksServer) CreateQueue(ctx context.Context, req *taskspb.CreateQueueRequest) (*taskspb.Queue, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return req.Queue, nil
+}
+
+// testPolicy retries quickly so tests don't sleep for long.
+var testPolicy = Policy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     5 * time.Millisecond,
+	Multiplier:     2,
+}
+
+// namedTask creates a task under a fixed name, so repeating it is safe.
+var namedTask = &taskspb.CreateTaskRequest{Parent: "queue", Task: &taskspb.Task{Name: "queue/tasks/game-1"}}
+
+// newTestClient starts the fake server on an in-memory listener and returns a
+// client that retries with the given policy.
+func newTestClient(t *testing.T, server *fakeCloudTasksServer, policy Policy) taskspb.CloudTasksClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	taskspb.RegisterCloudTasksServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(policy)),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial fake server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return taskspb.NewCloudTasksClient(conn)
+}
+
+// --- Classify ---
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Class
+	}{
+		{nil, Success},
+		{status.Error(codes.AlreadyExists, "exists"), Success},
+		{status.Error(codes.Unavailable, "down"), Retry},
+		{status.Error(codes.DeadlineExceeded, "slow"), Retry},
+		{status.Error(codes.ResourceExhausted, "quota"), Retry},
+		{status.Error(codes.InvalidArgument, "bad"), Fail},
+		{status.Error(codes.PermissionDenied, "denied"), Fail},
+		{errors.New("plain error"), Fail},
+		{Actionable("m", status.Error(codes.NotFound, "gone")), Fail},
+	}
+
+	for _, tt := range tests {
+		if got := Classify(tt.err); got != tt.want {
+			t.Errorf("Classify(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+// --- UnaryClientInterceptor ---
+
+func TestRetriesTransientErrors(t *testing.T) {
+	for _, code := range []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted} {
+		t.Run(code.String(), func(t *testing.T) {
+			server := &fakeCloudTasksServer{errs: []error{status.Error(code, "transient"), status.Error(code, "transient")}}
+			client := newTestClient(t, server, testPolicy)
+
+			task, err := client.CreateTask(context.Background(), namedTask)
+			if err != nil {
+				t.Fatalf("CreateTask() returned error: %v", err)
+			}
+			if task.Name != "queue/tasks/1" {
+				t.Errorf("task name = %q, want %q", task.Name, "queue/tasks/1")
+			}
+			if server.attempts != 3 {
+				t.Errorf("server saw %d attempts, want 3", server.attempts)
+			}
+		})
+	}
+}
+
+func TestGivesUpAfterMaxAttempts(t *testing.T) {
+	server := &fakeCloudTasksServer{errs: []error{
+		status.Error(codes.Unavailable, "down"),
+		status.Error(codes.Unavailable, "down"),
+		status.Error(codes.Unavailable, "down"),
+		status.Error(codes.Unavailable, "down"),
+	}}
+	client := newTestClient(t, server, testPolicy)
+
+	_, err := client.CreateTask(context.Background(), namedTask)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("status code = %s, want Unavailable", status.Code(err))
+	}
+	if !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("error = %q, want attempt count", err)
+	}
+	if server.attempts != 3 {
+		t.Errorf("server saw %d attempts, want 3", server.attempts)
+	}
+}
+
+func TestFailsFastWithActionableMessage(t *testing.T) {
+	tests := []struct {
+		code codes.Code
+		hint string
+	}{
+		{codes.InvalidArgument, "check -project, -location, -queue"},
+		{codes.PermissionDenied, "Cloud Tasks Enqueuer role"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			server := &fakeCloudTasksServer{errs: []error{status.Error(tt.code, "rejected")}}
+			client := newTestClient(t, server, testPolicy)
+
+			_, err := client.CreateTask(context.Background(), &taskspb.CreateTaskRequest{})
+			if status.Code(err) != tt.code {
+				t.Errorf("status code = %s, want %s", status.Code(err), tt.code)
+			}
+			if !strings.Contains(err.Error(), tt.hint) {
+				t.Errorf("error = %q, want hint %q", err, tt.hint)
+			}
+			if !strings.Contains(err.Error(), "CreateTask") {
+				t.Errorf("error = %q, want method name", err)
+			}
+			if server.attempts != 1 {
+				t.Errorf("server saw %d attempts, want 1", server.attempts)
+			}
+		})
+	}
+}
+
+func TestNonIdempotentRPCsAreNotRetried(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(taskspb.CloudTasksClient) error
+	}{
+		{"unnamed CreateTask", func(c taskspb.CloudTasksClient) error {
+			_, err := c.CreateTask(context.Background(), &taskspb.CreateTaskRequest{Parent: "queue", Task: &taskspb.Task{}})
+			return err
+		}},
+		{"RunTask", func(c taskspb.CloudTasksClient) error {
+			_, err := c.RunTask(context.Background(), &taskspb.RunTaskRequest{Name: "queue/tasks/game-1"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeCloudTasksServer{errs: []error{status.Error(codes.DeadlineExceeded, "slow")}}
+			err := tt.call(newTestClient(t, server, testPolicy))
+
+			if status.Code(err) != codes.DeadlineExceeded || !strings.Contains(err.Error(), "not retried") {
+				t.Errorf("error = %v, want DeadlineExceeded reported as not retried", err)
+			}
+			if server.attempts != 1 {
+				t.Errorf("server saw %d attempts, want 1", server.attempts)
+			}
+		})
+	}
+}
+
+func TestIdempotent(t *testing.T) {
+	tests := []struct {
+		req  interface{}
+		want bool
+	}{
+		{namedTask, true},
+		{&taskspb.CreateTaskRequest{Parent: "queue"}, false},
+		{&taskspb.RunTaskRequest{Name: "queue/tasks/game-1"}, false},
+		{&taskspb.DeleteTaskRequest{Name: "queue/tasks/game-1"}, true},
+		{&taskspb.ListTasksRequest{Parent: "queue"}, true},
+	}
+
+	for _, tt := range tests {
+		if got := Idempotent(tt.req); got != tt.want {
+			t.Errorf("Idempotent(%T %v) = %v, want %v", tt.req, tt.req, got, tt.want)
+		}
+	}
+}
+
+func TestAlreadyExistsIsNotRetried(t *testing.T) {
+	server := &fakeCloudTasksServer{errs: []error{status.Error(codes.AlreadyExists, "queue exists")}}
+	client := newTestClient(t, server, testPolicy)
+
+	_, err := client.CreateQueue(context.Background(), &taskspb.CreateQueueRequest{Queue: &taskspb.Queue{Name: "q"}})
+	if Classify(err) != Success {
+		t.Errorf("Classify(%v) = %s, want success", err, Classify(err))
+	}
+	if server.attempts != 1 {
+		t.Errorf("server saw %d attempts, want 1", server.attempts)
+	}
+}
+
+func TestStopsRetryingWhenContextCancelled(t *testing.T) {
+	server := &fakeCloudTasksServer{errs: []error{status.Error(codes.Unavailable, "down")}}
+	policy := testPolicy
+	policy.InitialBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	client := newTestClient(t, server, policy)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.CreateTask(ctx, &taskspb.CreateTaskRequest{})
+	if err == nil {
+		t.Fatal("CreateTask() succeeded, want error")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("CreateTask() took %s after cancellation", elapsed)
+	}
+	if server.attempts != 1 {
+		t.Errorf("server saw %d attempts, want 1", server.attempts)
+	}
+}
+
+// --- backoff ---
+
+func TestBackoffIsBounded(t *testing.T) {
+	policy := Policy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+	for n := 1; n <= 10; n++ {
+		d := policy.backoff(n)
+		if d < 80*time.Millisecond || d > 1200*time.Millisecond {
+			t.Errorf("backoff(%d) = %s, want within [80ms, 1.2s]", n, d)
+		}
+	}
+}
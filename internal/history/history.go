@@ -0,0 +1,210 @@
+// Package history records scheduling decisions in an embedded bbolt
+// database, so a run can tell which games changed since they were last
+// scheduled, replace their earlier tasks, and report what earlier runs did.
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Decision outcomes recorded for a game
+const (
+	StatusScheduled = "scheduled" // The game's tasks were created
+	StatusUnchanged = "unchanged" // The game matched its last scheduled record and was skipped
+	StatusFailed    = "failed"    // Creating the game's tasks failed
+)
+
+var (
+	// recordsBucket holds every record, keyed by a big-endian sequence number
+	recordsBucket = []byte("records")
+	// latestBucket holds the last scheduled or failed record of each game,
+	// keyed by sink and game ID
+	latestBucket = []byte("latest")
+)
+
+// ErrLocked is returned by Open when another process holds the database.
+var ErrLocked = errors.New("history database is in use by another run")
+
+// Record is one scheduling decision for a game.
+type Record struct {
+	GameID       int       `json:"gameId"`
+	StartTime    string    `json:"startTime"`   // Game start time the decision was made with
+	PayloadHash  string    `json:"payloadHash"` // Fingerprint of the tasks the game would get
+	TaskName     string    `json:"taskName,omitempty"`
+	ReminderTask string    `json:"reminderTask,omitempty"`
+	ScheduleTime time.Time `json:"scheduleTime,omitempty"` // When the tracking task runs
+	Sink         string    `json:"sink"`                   // Where the tasks were sent, e.g. a queue path
+	RunID        string    `json:"runId"`
+	Status       string    `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	RecordedAt   time.Time `json:"recordedAt"`
+}
+
+// Query selects records; zero fields match everything.
+type Query struct {
+	GameID int
+	RunID  string
+	Since  time.Time // Only records made at or after this time
+	Limit  int       // Maximum records returned; 0 for all
+}
+
+// matches reports whether the record satisfies the query's filters.
+func (q Query) matches(r Record) bool {
+	return (q.GameID == 0 || r.GameID == q.GameID) &&
+		(q.RunID == "" || r.RunID == q.RunID) &&
+		!r.RecordedAt.Before(q.Since)
+}
+
+// Store is an open history database.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens the database at path, creating it if needed. It waits up to a
+// second for another run to release the file before returning ErrLocked.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, latestBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// OpenReadOnly opens an existing database for queries, sharing it with other
+// readers. It returns an error wrapping os.ErrNotExist if there is none.
+func OpenReadOnly(path string) (*Store, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	db, err := bolt.Open(path, 0o644, &bolt.Options{Timeout: time.Second, ReadOnly: true})
+	if err != nil {
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("%w: %s", ErrLocked, path)
+		}
+		return nil, fmt.Errorf("failed to open history database %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database, releasing it for other runs.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add appends a record, stamping RecordedAt if it is zero. Scheduled and
+// failed records also become the game's latest record for their sink.
+func (s *Store) Add(record Record) error {
+	if record.RecordedAt.IsZero() {
+		record.RecordedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode history record: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(recordsBucket)
+		seq, err := records.NextSequence()
+		if err != nil {
+			return err
+		}
+		if err := records.Put(sequenceKey(seq), data); err != nil {
+			return err
+		}
+		if record.Status == StatusUnchanged {
+			return nil
+		}
+		return tx.Bucket(latestBucket).Put(latestKey(record.Sink, record.GameID), data)
+	})
+}
+
+// Latest returns the game's last scheduled or failed record for the sink;
+// ok is false if it has none.
+func (s *Store) Latest(sink string, gameID int) (record Record, ok bool, err error) {
+	err = s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(latestBucket)
+		if bucket == nil {
+			return nil
+		}
+		data := bucket.Get(latestKey(sink, gameID))
+		if data == nil {
+			return nil
+		}
+		ok = true
+		return json.Unmarshal(data, &record)
+	})
+	if err != nil {
+		return Record{}, false, fmt.Errorf("failed to read history for game %d: %w", gameID, err)
+	}
+	return record, ok, nil
+}
+
+// Find returns the records matching the query, newest first.
+func (s *Store) Find(query Query) ([]Record, error) {
+	var records []Record
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(recordsBucket)
+		if bucket == nil {
+			return nil
+		}
+		cursor := bucket.Cursor()
+		for key, data := cursor.Last(); key != nil; key, data = cursor.Prev() {
+			var record Record
+			if err := json.Unmarshal(data, &record); err != nil {
+				return fmt.Errorf("failed to decode history record %d: %w", binary.BigEndian.Uint64(key), err)
+			}
+			// Records are appended in time order, so older ones cannot match
+			if record.RecordedAt.Before(query.Since) {
+				break
+			}
+			if !query.matches(record) {
+				continue
+			}
+			records = append(records, record)
+			if query.Limit > 0 && len(records) == query.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// sequenceKey encodes a record's sequence number so keys sort in insertion order.
+func sequenceKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// latestKey identifies a game's latest record within a sink.
+func latestKey(sink string, gameID int) []byte {
+	return []byte(sink + "\x00" + strconv.Itoa(gameID))
+}
@@ -0,0 +1,131 @@
+package history
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) (*Store, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "history.db")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() returned error: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s, path
+}
+
+func TestLatestTracksScheduledAndFailedRecords(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	if _, ok, err := s.Latest("queue", 1); ok || err != nil {
+		t.Fatalf("Latest() on an empty store = %v, %v, want no record", ok, err)
+	}
+
+	records := []Record{
+		{GameID: 1, Sink: "queue", RunID: "run-1", PayloadHash: "a", TaskName: "tasks/1", Status: StatusScheduled},
+		{GameID: 1, Sink: "queue", RunID: "run-2", PayloadHash: "a", Status: StatusUnchanged},
+		{GameID: 1, Sink: "other", RunID: "run-2", PayloadHash: "b", Status: StatusFailed},
+	}
+	for _, record := range records {
+		if err := s.Add(record); err != nil {
+			t.Fatalf("Add() returned error: %v", err)
+		}
+	}
+
+	// Unchanged records leave the latest record alone, and sinks are tracked apart
+	latest, ok, err := s.Latest("queue", 1)
+	if err != nil || !ok {
+		t.Fatalf("Latest() = %v, %v, want a record", ok, err)
+	}
+	if latest.RunID != "run-1" || latest.TaskName != "tasks/1" || latest.RecordedAt.IsZero() {
+		t.Errorf("Latest() = %+v, want run-1's scheduled record", latest)
+	}
+	if latest, _, _ := s.Latest("other", 1); latest.Status != StatusFailed {
+		t.Errorf("Latest(other) = %+v, want the failed record", latest)
+	}
+}
+
+func TestFindFiltersNewestFirst(t *testing.T) {
+	s, _ := openTestStore(t)
+
+	start := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	for i, record := range []Record{
+		{GameID: 1, RunID: "run-1", Status: StatusScheduled},
+		{GameID: 2, RunID: "run-1", Status: StatusScheduled},
+		{GameID: 1, RunID: "run-2", Status: StatusUnchanged},
+		{GameID: 2, RunID: "run-2", Status: StatusFailed},
+	} {
+		record.RecordedAt = start.Add(time.Duration(i) * time.Hour)
+		if err := s.Add(record); err != nil {
+			t.Fatalf("Add() returned error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string // run:game of each record, newest first
+	}{
+		{"all", Query{}, []string{"run-2:2", "run-2:1", "run-1:2", "run-1:1"}},
+		{"by game", Query{GameID: 1}, []string{"run-2:1", "run-1:1"}},
+		{"by run", Query{RunID: "run-1"}, []string{"run-1:2", "run-1:1"}},
+		{"since", Query{Since: start.Add(90 * time.Minute)}, []string{"run-2:2", "run-2:1"}},
+		{"limit", Query{Limit: 1}, []string{"run-2:2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := s.Find(tt.query)
+			if err != nil {
+				t.Fatalf("Find() returned error: %v", err)
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, fmt.Sprintf("%s:%d", r.RunID, r.GameID))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Find() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Find() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOpenWaitsForOtherRuns(t *testing.T) {
+	s, path := openTestStore(t)
+	if err := s.Add(Record{GameID: 1, Status: StatusScheduled}); err != nil {
+		t.Fatalf("Add() returned error: %v", err)
+	}
+
+	if _, err := Open(path); !errors.Is(err, ErrLocked) {
+		t.Errorf("second Open() error = %v, want ErrLocked", err)
+	}
+
+	s.Close()
+	reader, err := OpenReadOnly(path)
+	if err != nil {
+		t.Fatalf("OpenReadOnly() returned error: %v", err)
+	}
+	defer reader.Close()
+	if records, _ := reader.Find(Query{}); len(records) != 1 {
+		t.Errorf("Find() after reopening = %d records, want 1", len(records))
+	}
+}
+
+func TestOpenReadOnlyMissingDatabase(t *testing.T) {
+	_, err := OpenReadOnly(filepath.Join(t.TempDir(), "missing.db"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("OpenReadOnly() error = %v, want os.ErrNotExist", err)
+	}
+}
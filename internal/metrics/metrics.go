@@ -0,0 +1,176 @@
+// Package metrics records Prometheus metrics for scheduling runs and exposes
+// them over HTTP or as a node_exporter textfile-collector file.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// namespace prefixes every metric name.
+const namespace = "gametask"
+
+// Metrics holds the counters and histograms recorded during a run.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	GamesFetched         prometheus.Counter
+	GamesFiltered        *prometheus.CounterVec
+	TasksCreated         prometheus.Counter
+	TasksFailed          prometheus.Counter
+	TasksAlreadyExisting prometheus.Counter
+	NHLAPILatency        *prometheus.HistogramVec
+	CloudTasksLatency    *prometheus.HistogramVec
+	NotificationFailures *prometheus.CounterVec
+	LastRunTimestamp     prometheus.Gauge
+	LastRunSuccess       prometheus.Gauge
+}
+
+// New creates a Metrics with all metrics registered on a fresh registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		GamesFetched: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "games_fetched_total",
+			Help:      "Games returned by the NHL API.",
+		}),
+		GamesFiltered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "games_filtered_total",
+			Help:      "Games dropped before scheduling, by reason.",
+		}, []string{"reason"}),
+		TasksCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_created_total",
+			Help:      "Cloud Tasks created.",
+		}),
+		TasksFailed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_failed_total",
+			Help:      "Cloud Tasks that could not be created.",
+		}),
+		TasksAlreadyExisting: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_already_existing_total",
+			Help:      "Cloud Tasks that already existed in the queue.",
+		}),
+		NHLAPILatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "nhl_api_request_duration_seconds",
+			Help:      "NHL API request latency, by outcome.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"outcome"}),
+		CloudTasksLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "cloudtasks_rpc_duration_seconds",
+			Help:      "Cloud Tasks RPC latency, by method and gRPC status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "code"}),
+		NotificationFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notification_failures_total",
+			Help:      "Notifications that could not be delivered, by event.",
+		}, []string{"event"}),
+		LastRunTimestamp: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time the last run finished.",
+		}),
+		LastRunSuccess: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "last_run_success",
+			Help:      "Whether the last run completed without aborting (1) or not (0).",
+		}),
+	}
+
+	m.registry.MustRegister(
+		m.GamesFetched,
+		m.GamesFiltered,
+		m.TasksCreated,
+		m.TasksFailed,
+		m.TasksAlreadyExisting,
+		m.NHLAPILatency,
+		m.CloudTasksLatency,
+		m.NotificationFailures,
+		m.LastRunTimestamp,
+		m.LastRunSuccess,
+	)
+
+	return m
+}
+
+// ObserveNHLAPI records the latency of an NHL API request that started at start.
+func (m *Metrics) ObserveNHLAPI(start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.NHLAPILatency.WithLabelValues(outcome).Observe(time.Since(start).Seconds())
+}
+
+// FinishRun records the end of a run and whether it completed.
+func (m *Metrics) FinishRun(success bool) {
+	m.LastRunTimestamp.SetToCurrentTime()
+	if success {
+		m.LastRunSuccess.Set(1)
+	} else {
+		m.LastRunSuccess.Set(0)
+	}
+}
+
+// UnaryClientInterceptor returns a gRPC interceptor that records the latency
+// and status code of every unary RPC on the connection.
+func (m *Metrics) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		m.CloudTasksLatency.WithLabelValues(method, status.Code(err).String()).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+// Handler returns an HTTP handler serving the metrics in Prometheus format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve starts an HTTP listener on addr serving the metrics at /metrics.
+// It returns once the listener is bound; call the returned function to stop it.
+func (m *Metrics) Serve(addr string) (func(context.Context) error, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Warn("Metrics server stopped", "error", err)
+		}
+	}()
+
+	return server.Shutdown, nil
+}
+
+// WriteTextfile writes the metrics to path in the node_exporter
+// textfile-collector format. The file is replaced atomically.
+func (m *Metrics) WriteTextfile(path string) error {
+	if err := prometheus.WriteToTextfile(path, m.registry); err != nil {
+		return fmt.Errorf("failed to write metrics textfile: %w", err)
+	}
+	return nil
+}
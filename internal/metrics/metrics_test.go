@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWriteTextfile(t *testing.T) {
+	m := New()
+	m.GamesFetched.Add(16)
+	m.TasksCreated.Add(3)
+	m.GamesFiltered.WithLabelValues("team").Add(13)
+	m.FinishRun(true)
+
+	path := filepath.Join(t.TempDir(), "gametask.prom")
+	if err := m.WriteTextfile(path); err != nil {
+		t.Fatalf("WriteTextfile() returned error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read textfile: %v", err)
+	}
+	for _, want := range []string{
+		"gametask_games_fetched_total 16",
+		"gametask_tasks_created_total 3",
+		`gametask_games_filtered_total{reason="team"} 13`,
+		"gametask_last_run_success 1",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("textfile missing %q, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestWriteTextfile_BadPath(t *testing.T) {
+	m := New()
+	if err := m.WriteTextfile(filepath.Join(t.TempDir(), "missing", "gametask.prom")); err == nil {
+		t.Error("WriteTextfile() to missing directory returned nil error")
+	}
+}
+
+func TestUnaryClientInterceptor_RecordsStatusCode(t *testing.T) {
+	m := New()
+	interceptor := m.UnaryClientInterceptor()
+
+	failing := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return status.Error(codes.Unavailable, "emulator down")
+	}
+	succeeding := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return nil
+	}
+
+	method := "/google.cloud.tasks.v2.CloudTasks/CreateTask"
+	if err := interceptor(context.Background(), method, nil, nil, nil, failing); status.Code(err) != codes.Unavailable {
+		t.Errorf("interceptor error = %v, want Unavailable passed through", err)
+	}
+	interceptor(context.Background(), method, nil, nil, nil, succeeding)
+
+	if n := testutil.CollectAndCount(m.CloudTasksLatency); n != 2 {
+		t.Errorf("latency series = %d, want 2 (OK and Unavailable)", n)
+	}
+}
+
+func TestObserveNHLAPI(t *testing.T) {
+	m := New()
+	m.ObserveNHLAPI(time.Now(), nil)
+	m.ObserveNHLAPI(time.Now(), errors.New("timeout"))
+
+	if n := testutil.CollectAndCount(m.NHLAPILatency); n != 2 {
+		t.Errorf("latency series = %d, want 2 (success and error)", n)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.NotificationFailures.WithLabelValues("run_completed").Inc()
+
+	server := httptest.NewServer(m.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET metrics failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if !strings.Contains(string(body), `gametask_notification_failures_total{event="run_completed"} 1`) {
+		t.Errorf("metrics output missing notification failure, got:\n%s", body)
+	}
+}
+
+func TestServe(t *testing.T) {
+	m := New()
+	shutdown, err := m.Serve("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Serve() returned error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown returned error: %v", err)
+	}
+}
@@ -0,0 +1,200 @@
+package notification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLang is the language used when none is configured.
+const DefaultLang = "en"
+
+// plural holds the singular and plural forms of a counted phrase, each
+// containing a single %d verb.
+type plural struct {
+	One   string
+	Other string
+}
+
+// Messages is a message catalog holding the localized text used in
+// notifications for one language.
+type Messages struct {
+	// Lang is the catalog's language code, e.g. "fr".
+	Lang string
+	// NameKey is the key used to look up names in the NHL API's localized
+	// name maps. English names live under "default".
+	NameKey string
+
+	ScheduleTitle   string
+	NoGames         string
+	GamesScheduled  plural
+	GamesSkipped    plural
+	GamesFailed     plural
+	GamesUnchanged  plural
+	StartField      string
+	DateAtTime      string // Date, time; used when a start time can't be parsed
+	LocalTimeField  string
+	VenueTimeField  string
+	TimeFormat      string // Go time layout for local and venue times
+	GameLabel       string
+	SkippedField    string
+	FailedField     string
+	NoReason        string
+	RunAbortedTitle string
+
+	GameStarted      string // Reason a game that already started was skipped
+	InvalidStartTime string // Start time; reason a game with an unparsable start was skipped
+	UnchangedSince   string // Run ID; reason an unchanged game was not scheduled again
+
+	RunStarted      string // Date
+	TestModeSuffix  string
+	GameScheduled   string // Matchup, start time
+	GameSkipped     string // Matchup, reason
+	TaskFailed      string // Matchup, error
+	ScheduleChanged string // Matchup, previous start time, new start time
+	Reminder        plural // Matchup, minutes; uses %[1]s and %[2]d
+
+	// TeamNameFormat renders a full team name from its place name, common
+	// name and place name with preposition (%[1]s, %[2]s, %[3]s).
+	TeamNameFormat string
+
+	// zeroIsSingular reports whether a count of zero takes the singular form.
+	zeroIsSingular bool
+}
+
+// catalogs holds every supported message catalog, keyed by language code.
+var catalogs = map[string]*Messages{
+	"en": {
+		Lang:             "en",
+		NameKey:          "default",
+		ScheduleTitle:    "NHL Game Schedule",
+		NoGames:          "No games were identified to schedule.",
+		GamesScheduled:   plural{One: "%d game scheduled", Other: "%d games scheduled"},
+		GamesSkipped:     plural{One: "%d skipped", Other: "%d skipped"},
+		GamesFailed:      plural{One: "%d failed", Other: "%d failed"},
+		GamesUnchanged:   plural{One: "%d unchanged", Other: "%d unchanged"},
+		StartField:       "Start",
+		DateAtTime:       "%s at %s",
+		LocalTimeField:   "Local time",
+		VenueTimeField:   "Venue time",
+		TimeFormat:       "Mon Jan 2, 3:04 PM MST",
+		GameLabel:        "Game",
+		SkippedField:     "Skipped",
+		FailedField:      "Failed",
+		NoReason:         "No reason given",
+		RunAbortedTitle:  "NHL Game Schedule run aborted",
+		GameStarted:      "Game has already started",
+		InvalidStartTime: "Invalid start time %q",
+		UnchangedSince:   "Unchanged since run %s",
+		RunStarted:       "Scheduling NHL games for %s",
+		TestModeSuffix:   " (test mode)",
+		GameScheduled:    "Scheduled %s at %s",
+		GameSkipped:      "Skipped %s: %s",
+		TaskFailed:       "Failed to schedule %s: %v",
+		ScheduleChanged:  "%s start time changed from %s to %s",
+		Reminder:         plural{One: "%[1]s starts in %[2]d minute", Other: "%[1]s starts in %[2]d minutes"},
+		TeamNameFormat:   "%[1]s %[2]s",
+	},
+	"fr": {
+		Lang:             "fr",
+		NameKey:          "fr",
+		ScheduleTitle:    "Calendrier des matchs de la LNH",
+		NoGames:          "Aucun match à programmer n'a été trouvé.",
+		GamesScheduled:   plural{One: "%d match programmé", Other: "%d matchs programmés"},
+		GamesSkipped:     plural{One: "%d ignoré", Other: "%d ignorés"},
+		GamesFailed:      plural{One: "%d en échec", Other: "%d en échec"},
+		GamesUnchanged:   plural{One: "%d inchangé", Other: "%d inchangés"},
+		StartField:       "Début",
+		DateAtTime:       "%s à %s",
+		LocalTimeField:   "Heure locale",
+		VenueTimeField:   "Heure de l'aréna",
+		TimeFormat:       "02/01/2006 15:04 MST",
+		GameLabel:        "Match",
+		SkippedField:     "Ignoré",
+		FailedField:      "Échec",
+		NoReason:         "Aucune raison indiquée",
+		RunAbortedTitle:  "Programmation des matchs de la LNH interrompue",
+		GameStarted:      "Le match a déjà commencé",
+		InvalidStartTime: "Heure de début invalide %q",
+		UnchangedSince:   "Inchangé depuis l'exécution %s",
+		RunStarted:       "Programmation des matchs de la LNH du %s",
+		TestModeSuffix:   " (mode test)",
+		GameScheduled:    "%s programmé à %s",
+		GameSkipped:      "%s ignoré : %s",
+		TaskFailed:       "Échec de la programmation de %s : %v",
+		ScheduleChanged:  "L'heure de début de %s est passée de %s à %s",
+		Reminder:         plural{One: "%[1]s commence dans %[2]d minute", Other: "%[1]s commence dans %[2]d minutes"},
+		TeamNameFormat:   "%[2]s %[3]s",
+		zeroIsSingular:   true,
+	},
+}
+
+// MessagesFor returns the message catalog for a language code such as "fr".
+// Returns an error if the language is not supported.
+func MessagesFor(lang string) (*Messages, error) {
+	if lang == "" {
+		lang = DefaultLang
+	}
+	if m, ok := catalogs[strings.ToLower(lang)]; ok {
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported language %q (supported: %s)", lang, strings.Join(SupportedLangs(), ", "))
+}
+
+// defaultMessages returns the catalog for DefaultLang.
+func defaultMessages() *Messages {
+	return catalogs[DefaultLang]
+}
+
+// SupportedLangs returns the supported language codes in sorted order.
+func SupportedLangs() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Count renders a counted phrase in the catalog's language.
+func (m *Messages) Count(p plural, n int) string {
+	if n == 1 || (n == 0 && m.zeroIsSingular) {
+		return fmt.Sprintf(p.One, n)
+	}
+	return fmt.Sprintf(p.Other, n)
+}
+
+// ReminderText renders a pre-game reminder, e.g. "DAL @ BOS starts in 30 minutes".
+func (m *Messages) ReminderText(matchup string, minutes int) string {
+	p := m.Reminder
+	format := p.Other
+	if minutes == 1 || (minutes == 0 && m.zeroIsSingular) {
+		format = p.One
+	}
+	return fmt.Sprintf(format, matchup, minutes)
+}
+
+// reason returns the reason for a skipped or failed game, or a placeholder
+// if none was given.
+func (m *Messages) reason(reason string) string {
+	if reason == "" {
+		return m.NoReason
+	}
+	return reason
+}
+
+// Name looks up a localized name in one of the NHL API's name maps, falling
+// back to the "default" entry when the catalog's language is missing.
+func (m *Messages) Name(names map[string]string) string {
+	if name, ok := names[m.NameKey]; ok && name != "" {
+		return name
+	}
+	return names["default"]
+}
+
+// TeamName renders a team's full name from the NHL API's localized name
+// maps, e.g. "Montreal Canadiens" or "Canadiens de Montréal".
+func (m *Messages) TeamName(placeName, commonName, placeNameWithPreposition map[string]string) string {
+	name := fmt.Sprintf(m.TeamNameFormat, m.Name(placeName), m.Name(commonName), m.Name(placeNameWithPreposition))
+	return strings.Join(strings.Fields(name), " ")
+}
@@ -0,0 +1,145 @@
+package notification
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Montreal's name maps as returned by the NHL API
+var (
+	mtlPlaceName  = map[string]string{"default": "Montréal", "fr": "Montréal"}
+	mtlCommonName = map[string]string{"default": "Canadiens", "fr": "Canadiens"}
+	mtlPlaceWith  = map[string]string{"default": "Montréal", "fr": "de Montréal"}
+)
+
+func TestMessagesFor(t *testing.T) {
+	for _, lang := range []string{"", "en", "EN", "fr"} {
+		if _, err := MessagesFor(lang); err != nil {
+			t.Errorf("MessagesFor(%q) returned error: %v", lang, err)
+		}
+	}
+
+	_, err := MessagesFor("de")
+	if err == nil {
+		t.Fatal("MessagesFor(\"de\") returned nil error, want unsupported language")
+	}
+	if !strings.Contains(err.Error(), "en, fr") {
+		t.Errorf("error = %q, want it to list supported languages", err.Error())
+	}
+}
+
+func TestMessages_TeamName(t *testing.T) {
+	en, _ := MessagesFor("en")
+	if got := en.TeamName(mtlPlaceName, mtlCommonName, mtlPlaceWith); got != "Montréal Canadiens" {
+		t.Errorf("en TeamName() = %q, want %q", got, "Montréal Canadiens")
+	}
+
+	fr, _ := MessagesFor("fr")
+	if got := fr.TeamName(mtlPlaceName, mtlCommonName, mtlPlaceWith); got != "Canadiens de Montréal" {
+		t.Errorf("fr TeamName() = %q, want %q", got, "Canadiens de Montréal")
+	}
+}
+
+func TestMessages_NameFallsBackToDefault(t *testing.T) {
+	fr, _ := MessagesFor("fr")
+	names := map[string]string{"default": "Stars"}
+	if got := fr.Name(names); got != "Stars" {
+		t.Errorf("Name() = %q, want default entry %q", got, "Stars")
+	}
+	if got := fr.TeamName(map[string]string{"default": "Dallas"}, names, nil); got != "Stars" {
+		t.Errorf("TeamName() with missing preposition = %q, want %q", got, "Stars")
+	}
+}
+
+func TestMessages_Count(t *testing.T) {
+	en, _ := MessagesFor("en")
+	fr, _ := MessagesFor("fr")
+
+	tests := []struct {
+		m    *Messages
+		n    int
+		want string
+	}{
+		{en, 0, "0 games scheduled"},
+		{en, 1, "1 game scheduled"},
+		{en, 2, "2 games scheduled"},
+		{fr, 0, "0 match programmé"},
+		{fr, 1, "1 match programmé"},
+		{fr, 3, "3 matchs programmés"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Count(tt.m.GamesScheduled, tt.n); got != tt.want {
+			t.Errorf("%s Count(%d) = %q, want %q", tt.m.Lang, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMessages_ReminderText(t *testing.T) {
+	en, _ := MessagesFor("en")
+	if got := en.ReminderText("DAL @ BOS", 30); got != "DAL @ BOS starts in 30 minutes" {
+		t.Errorf("en ReminderText() = %q", got)
+	}
+	fr, _ := MessagesFor("fr")
+	if got := fr.ReminderText("DAL @ BOS", 1); got != "DAL @ BOS commence dans 1 minute" {
+		t.Errorf("fr ReminderText() = %q", got)
+	}
+}
+
+func TestDiscordSender_SendScheduleSummary_French(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	fr, _ := MessagesFor("fr")
+	games := []GameInfo{
+		{ID: "1", StartTime: "2024-01-01T19:00:00Z", HomeTeam: "MTL", AwayTeam: "TOR", HomeTeamName: "Canadiens de Montréal", AwayTeamName: "Maple Leafs de Toronto"},
+		{ID: "2", StartTime: "2024-01-01T20:00:00Z", HomeTeam: "OTT", AwayTeam: "BOS", Status: GameStatusSkipped},
+	}
+
+	s := NewDiscordSenderWithOptions(server.URL, DiscordOptions{Messages: fr})
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	if want := "Calendrier des matchs de la LNH (1 match programmé, 1 ignoré)"; received.Embeds[0].Title != want {
+		t.Errorf("title = %q, want %q", received.Embeds[0].Title, want)
+	}
+	game := received.Embeds[1]
+	if game.Title != "Maple Leafs de Toronto @ Canadiens de Montréal" {
+		t.Errorf("game title = %q, want French full names", game.Title)
+	}
+	if game.Fields[0].Name != "Début" {
+		t.Errorf("start field name = %q, want %q", game.Fields[0].Name, "Début")
+	}
+	skipped := received.Embeds[2].Fields[1]
+	if skipped.Name != "Ignoré" || skipped.Value != "Aucune raison indiquée" {
+		t.Errorf("skipped field = %+v, want French label and placeholder", skipped)
+	}
+}
+
+func TestDiscordSender_SendScheduleSummary_FrenchNoGames(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	fr, _ := MessagesFor("fr")
+	s := NewDiscordSenderWithOptions(server.URL, DiscordOptions{Messages: fr})
+	if err := s.SendScheduleSummary(nil); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	if received.Embeds[0].Description != fr.NoGames {
+		t.Errorf("description = %q, want %q", received.Embeds[0].Description, fr.NoGames)
+	}
+}
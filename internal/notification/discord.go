@@ -2,22 +2,71 @@ package notification
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"time"
+	"unicode/utf8"
+)
+
+// Discord webhook limits, counted in characters.
+// See https://discord.com/developers/docs/resources/message#embed-object-embed-limits
+const (
+	maxMessageEmbedLength = 6000
+	maxEmbedsPerMessage   = 10
+	maxContentLength      = 2000
+	maxTitleLength        = 256
+	maxDescriptionLength  = 4096
+	maxFieldNameLength    = 256
+	maxFieldValueLength   = 1024
+	maxFooterLength       = 2048
+)
+
+// Embed colors used for run status.
+const (
+	colorGreen  = 3066993
+	colorOrange = 15105570
+	colorRed    = 15158332
 )
 
 // DiscordSender sends notifications via Discord webhooks.
 type DiscordSender struct {
 	webhookURL string
 	httpClient *http.Client
+	options    DiscordOptions
+	messages   *Messages
+}
+
+// DiscordOptions customizes how a DiscordSender renders its messages.
+type DiscordOptions struct {
+	// MentionRoleID is the ID of a Discord role to mention (e.g. @Stars-Fans)
+	// in schedule summaries and alerts. Empty disables mentions.
+	MentionRoleID string
+
+	// Messages is the message catalog used to render text. Nil uses English.
+	Messages *Messages
+
+	// Location is the time zone game times are also shown in, alongside the
+	// venue's own time zone. Nil shows only the venue time.
+	Location *time.Location
+
+	// HTTPClient sends the webhook requests. Nil uses a client with a
+	// 10 second timeout.
+	HTTPClient *http.Client
 }
 
 // discordMessage represents the payload structure for Discord webhook messages.
 type discordMessage struct {
-	Content string         `json:"content,omitempty"`
-	Embeds  []discordEmbed `json:"embeds,omitempty"`
+	Content         string                  `json:"content,omitempty"`
+	Embeds          []discordEmbed          `json:"embeds,omitempty"`
+	AllowedMentions *discordAllowedMentions `json:"allowed_mentions,omitempty"`
+}
+
+// discordAllowedMentions restricts which mentions in a message ping anyone.
+type discordAllowedMentions struct {
+	Roles []string `json:"roles"`
 }
 
 // discordEmbed represents an embed in a Discord message.
@@ -26,9 +75,21 @@ type discordEmbed struct {
 	Description string              `json:"description,omitempty"`
 	Color       int                 `json:"color,omitempty"`
 	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Thumbnail   *discordEmbedImage  `json:"thumbnail,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
 	Timestamp   string              `json:"timestamp,omitempty"`
 }
 
+// discordEmbedImage represents a thumbnail or image in a Discord embed.
+type discordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+// discordEmbedFooter represents the footer of a Discord embed.
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
 // discordEmbedField represents a field in a Discord embed.
 type discordEmbedField struct {
 	Name   string `json:"name"`
@@ -39,66 +100,139 @@ type discordEmbedField struct {
 // NewDiscordSender creates a new Discord notification sender.
 // Returns a NoOpSender if the webhook URL is empty.
 func NewDiscordSender(webhookURL string) Sender {
+	return NewDiscordSenderWithOptions(webhookURL, DiscordOptions{})
+}
+
+// NewDiscordSenderWithOptions creates a new Discord notification sender with
+// the given rendering options.
+// Returns a NoOpSender if the webhook URL is empty.
+func NewDiscordSenderWithOptions(webhookURL string, options DiscordOptions) Sender {
 	if webhookURL == "" {
 		return NewNoOpSender()
 	}
 
+	messages := options.Messages
+	if messages == nil {
+		messages = defaultMessages()
+	}
+
+	httpClient := options.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{
+			Timeout: 10 * time.Second,
+		}
+	}
+
 	return &DiscordSender{
 		webhookURL: webhookURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		httpClient: httpClient,
+		options:    options,
+		messages:   messages,
 	}
 }
 
 // Send sends a simple text message to Discord.
 func (d *DiscordSender) Send(message string) error {
+	return d.SendContext(context.Background(), message)
+}
+
+// SendContext is Send bound to ctx.
+func (d *DiscordSender) SendContext(ctx context.Context, message string) error {
 	payload := discordMessage{
 		Content: message,
 	}
 
-	return d.sendPayload(payload)
+	return d.sendPayload(ctx, payload)
 }
 
-// SendScheduleSummary sends a summary of all scheduled games to Discord.
-// If no games were scheduled, sends a message indicating that.
+// SendScheduleSummary sends a summary of all processed games to Discord.
+// If no games were identified, sends a message indicating that.
+// The header counts scheduled, skipped and failed games and is colored green
+// when every game was scheduled, orange on partial failure and red when no
+// game could be scheduled.
+// Each game is rendered as its own embed, in start-time order, using Discord
+// timestamp markup so every reader sees the start time in their own time zone.
+// Games unchanged since an earlier run are only counted in the header.
+// Summaries that exceed Discord's per-message limits are split across several
+// messages, with each part marked in its header title (e.g. "1/3").
 func (d *DiscordSender) SendScheduleSummary(games []GameInfo) error {
-	var embed discordEmbed
+	return d.SendScheduleSummaryContext(context.Background(), games)
+}
 
+// SendScheduleSummaryContext is SendScheduleSummary bound to ctx.
+func (d *DiscordSender) SendScheduleSummaryContext(ctx context.Context, games []GameInfo) error {
 	if len(games) == 0 {
-		embed = discordEmbed{
-			Title:       "NHL Game Schedule",
-			Description: "No games were identified to schedule.",
+		embed := discordEmbed{
+			Title:       d.messages.ScheduleTitle,
+			Description: d.messages.NoGames,
 			Color:       9807270, // Gray
 			Timestamp:   time.Now().UTC().Format(time.RFC3339),
 		}
-	} else {
-		// Build description with all games
-		var description string
-		for _, game := range games {
-			description += fmt.Sprintf("**%s @ %s**\n%s at %s\n\n",
-				game.AwayTeam, game.HomeTeam, game.GameDate, game.StartTime)
+		return d.sendPayload(ctx, d.withMention(discordMessage{Embeds: []discordEmbed{embed}}))
+	}
+
+	sorted := make([]GameInfo, len(games))
+	copy(sorted, games)
+	sortGamesByStartTime(sorted)
+
+	title, color := summaryHeader(d.messages, sorted)
+
+	var gameEmbeds []discordEmbed
+	for _, game := range sorted {
+		if game.Status != GameStatusUnchanged {
+			gameEmbeds = append(gameEmbeds, gameEmbed(d.messages, d.options.Location, game))
 		}
+	}
 
-		title := fmt.Sprintf("NHL Game Schedule (%d game", len(games))
-		if len(games) != 1 {
-			title += "s"
+	// Every message starts with a header embed, so reserve room for it
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	header := discordEmbed{
+		Title:     title + " 00/00",
+		Color:     color,
+		Timestamp: timestamp,
+	}
+	parts := packEmbeds(gameEmbeds, maxEmbedsPerMessage-1, maxMessageEmbedLength-embedLength(header))
+	if len(parts) == 0 {
+		// Every game was unchanged; the header alone reports the run
+		parts = [][]discordEmbed{nil}
+	}
+
+	for i, part := range parts {
+		header.Title = title
+		if len(parts) > 1 {
+			header.Title = fmt.Sprintf("%s %d/%d", title, i+1, len(parts))
 		}
-		title += " scheduled)"
 
-		embed = discordEmbed{
-			Title:       title,
-			Description: description,
-			Color:       3066993, // Green
-			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		message := discordMessage{Embeds: append([]discordEmbed{header}, part...)}
+		if i == 0 {
+			message = d.withMention(message)
+		}
+		if err := d.sendPayload(ctx, message); err != nil {
+			if len(parts) > 1 {
+				return fmt.Errorf("failed to send schedule summary message %d/%d: %w", i+1, len(parts), err)
+			}
+			return err
 		}
 	}
 
-	payload := discordMessage{
-		Embeds: []discordEmbed{embed},
+	return nil
+}
+
+// SendAlert sends a red alert embed to Discord, used when a run aborts.
+func (d *DiscordSender) SendAlert(title, message string) error {
+	return d.SendAlertContext(context.Background(), title, message)
+}
+
+// SendAlertContext is SendAlert bound to ctx.
+func (d *DiscordSender) SendAlertContext(ctx context.Context, title, message string) error {
+	embed := discordEmbed{
+		Title:       title,
+		Description: message,
+		Color:       colorRed,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
 	}
 
-	return d.sendPayload(payload)
+	return d.sendPayload(ctx, d.withMention(discordMessage{Embeds: []discordEmbed{embed}}))
 }
 
 // IsEnabled returns true if the Discord sender has a configured webhook URL.
@@ -106,14 +240,26 @@ func (d *DiscordSender) IsEnabled() bool {
 	return d.webhookURL != ""
 }
 
+// withMention adds the configured role mention to a message, allowing only
+// that role to be pinged.
+func (d *DiscordSender) withMention(message discordMessage) discordMessage {
+	if d.options.MentionRoleID == "" {
+		return message
+	}
+
+	message.Content = fmt.Sprintf("<@&%s>", d.options.MentionRoleID)
+	message.AllowedMentions = &discordAllowedMentions{Roles: []string{d.options.MentionRoleID}}
+	return message
+}
+
 // sendPayload sends a Discord message payload to the webhook URL.
-func (d *DiscordSender) sendPayload(payload discordMessage) error {
-	jsonPayload, err := json.Marshal(payload)
+func (d *DiscordSender) sendPayload(ctx context.Context, payload discordMessage) error {
+	jsonPayload, err := json.Marshal(limitMessage(payload))
 	if err != nil {
 		return fmt.Errorf("failed to marshal Discord payload: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, d.webhookURL, bytes.NewBuffer(jsonPayload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.webhookURL, bytes.NewBuffer(jsonPayload))
 	if err != nil {
 		return fmt.Errorf("failed to create Discord request: %w", err)
 	}
@@ -133,3 +279,206 @@ func (d *DiscordSender) sendPayload(payload discordMessage) error {
 
 	return nil
 }
+
+// sortGamesByStartTime sorts games by start time, earliest first.
+// Games whose start time cannot be parsed keep their relative order at the end.
+func sortGamesByStartTime(games []GameInfo) {
+	sort.SliceStable(games, func(i, j int) bool {
+		ti, errI := time.Parse(time.RFC3339, games[i].StartTime)
+		tj, errJ := time.Parse(time.RFC3339, games[j].StartTime)
+		if errI != nil || errJ != nil {
+			return errI == nil && errJ != nil
+		}
+		return ti.Before(tj)
+	})
+}
+
+// summaryHeader returns the summary title and color for a set of games,
+// based on how many of them were scheduled, skipped, failed or unchanged.
+func summaryHeader(m *Messages, games []GameInfo) (string, int) {
+	var scheduled, skipped, failed, unchanged int
+	for _, game := range games {
+		switch game.Status {
+		case GameStatusSkipped:
+			skipped++
+		case GameStatusFailed:
+			failed++
+		case GameStatusUnchanged:
+			unchanged++
+		default:
+			scheduled++
+		}
+	}
+
+	title := m.ScheduleTitle + " (" + m.Count(m.GamesScheduled, scheduled)
+	if skipped > 0 {
+		title += ", " + m.Count(m.GamesSkipped, skipped)
+	}
+	if failed > 0 {
+		title += ", " + m.Count(m.GamesFailed, failed)
+	}
+	if unchanged > 0 {
+		title += ", " + m.Count(m.GamesUnchanged, unchanged)
+	}
+	title += ")"
+
+	color := colorGreen
+	if failed > 0 {
+		color = colorOrange
+		if scheduled == 0 {
+			color = colorRed
+		}
+	}
+
+	return title, color
+}
+
+// gameEmbed renders a single game as a Discord embed, branded with the
+// home team's color and logo.
+func gameEmbed(m *Messages, loc *time.Location, game GameInfo) discordEmbed {
+	start := fmt.Sprintf(m.DateAtTime, game.GameDate, game.StartTime)
+	startTime, err := time.Parse(time.RFC3339, game.StartTime)
+	if err == nil {
+		unix := startTime.Unix()
+		start = fmt.Sprintf("<t:%d:F> (<t:%d:R>)", unix, unix)
+	}
+
+	embed := discordEmbed{
+		Title: fmt.Sprintf("%s @ %s", teamDisplayName(game.AwayTeamName, game.AwayTeam),
+			teamDisplayName(game.HomeTeamName, game.HomeTeam)),
+		Color: teamColor(game.HomeTeamID),
+		Fields: []discordEmbedField{
+			{Name: m.StartField, Value: start},
+		},
+	}
+	// Fixed local and venue times complement the reader-relative timestamp
+	if err == nil {
+		if loc != nil {
+			embed.Fields = append(embed.Fields, discordEmbedField{
+				Name: m.LocalTimeField, Value: formatStartTime(m, loc, game.StartTime), Inline: true,
+			})
+		}
+		if game.VenueTimezone != "" {
+			if venue, err := time.LoadLocation(game.VenueTimezone); err == nil {
+				embed.Fields = append(embed.Fields, discordEmbedField{
+					Name: m.VenueTimeField, Value: startTime.In(venue).Format(m.TimeFormat), Inline: true,
+				})
+			}
+		}
+	}
+	switch game.Status {
+	case GameStatusSkipped:
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: m.SkippedField, Value: m.reason(game.Reason)})
+	case GameStatusFailed:
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: m.FailedField, Value: m.reason(game.Reason)})
+		embed.Color = colorRed
+	}
+	if game.HomeTeam != "" {
+		embed.Thumbnail = &discordEmbedImage{URL: teamLogoURL(game.HomeTeam)}
+	}
+	if game.ID != "" {
+		embed.Footer = &discordEmbedFooter{Text: fmt.Sprintf("%s @ %s · %s %s", game.AwayTeam, game.HomeTeam, m.GameLabel, game.ID)}
+	}
+
+	return limitEmbed(embed)
+}
+
+// limitMessage truncates a message's content and embeds to Discord's limits,
+// which otherwise reject the whole message.
+func limitMessage(message discordMessage) discordMessage {
+	message.Content = truncate(message.Content, maxContentLength)
+	embeds := make([]discordEmbed, len(message.Embeds))
+	for i, embed := range message.Embeds {
+		embeds[i] = limitEmbed(embed)
+	}
+	message.Embeds = embeds
+	return message
+}
+
+// limitEmbed truncates each part of an embed to its Discord limit.
+func limitEmbed(embed discordEmbed) discordEmbed {
+	embed.Title = truncate(embed.Title, maxTitleLength)
+	embed.Description = truncate(embed.Description, maxDescriptionLength)
+	if embed.Fields != nil {
+		fields := make([]discordEmbedField, len(embed.Fields))
+		for i, field := range embed.Fields {
+			field.Name = truncate(field.Name, maxFieldNameLength)
+			field.Value = truncate(field.Value, maxFieldValueLength)
+			fields[i] = field
+		}
+		embed.Fields = fields
+	}
+	if embed.Footer != nil {
+		embed.Footer = &discordEmbedFooter{Text: truncate(embed.Footer.Text, maxFooterLength)}
+	}
+	return embed
+}
+
+// truncate shortens s to at most max characters, ending it with an ellipsis
+// when anything was cut.
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
+
+// formatStartTime renders an RFC3339 start time in loc with the catalog's
+// time layout. Without a location, or if the time can't be parsed, the
+// start time is returned unchanged.
+func formatStartTime(m *Messages, loc *time.Location, startTime string) string {
+	if loc == nil {
+		return startTime
+	}
+	t, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return startTime
+	}
+	return t.In(loc).Format(m.TimeFormat)
+}
+
+// teamDisplayName returns the team's full name, falling back to its abbreviation.
+func teamDisplayName(fullName, abbrev string) string {
+	if fullName != "" {
+		return fullName
+	}
+	return abbrev
+}
+
+// packEmbeds groups embeds into chunks of at most maxCount embeds whose
+// combined length stays within maxLength characters.
+func packEmbeds(embeds []discordEmbed, maxCount, maxLength int) [][]discordEmbed {
+	var chunks [][]discordEmbed
+	var current []discordEmbed
+	currentLen := 0
+
+	for _, embed := range embeds {
+		embedLen := embedLength(embed)
+		if len(current) > 0 && (len(current) == maxCount || currentLen+embedLen > maxLength) {
+			chunks = append(chunks, current)
+			current = nil
+			currentLen = 0
+		}
+		current = append(current, embed)
+		currentLen += embedLen
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
+	}
+
+	return chunks
+}
+
+// embedLength returns the number of characters Discord counts toward the
+// combined embed limit of a message.
+func embedLength(embed discordEmbed) int {
+	n := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		n += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	if embed.Footer != nil {
+		n += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	return n
+}
@@ -0,0 +1,278 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventKind identifies the type of a notification event.
+type EventKind string
+
+const (
+	// KindRunStarted is emitted when a scheduling run begins.
+	KindRunStarted EventKind = "run_started"
+	// KindGameScheduled is emitted when a task is created for a game.
+	KindGameScheduled EventKind = "game_scheduled"
+	// KindGameSkipped is emitted when a game is deliberately not scheduled.
+	KindGameSkipped EventKind = "game_skipped"
+	// KindTaskFailed is emitted when creating a game's task fails.
+	KindTaskFailed EventKind = "task_failed"
+	// KindRunCompleted is emitted once all games have been processed.
+	KindRunCompleted EventKind = "run_completed"
+	// KindRunAborted is emitted when a run stops before processing games.
+	KindRunAborted EventKind = "run_aborted"
+	// KindScheduleChanged is emitted when a game's start time differs from
+	// the one it was previously scheduled with.
+	KindScheduleChanged EventKind = "schedule_changed"
+)
+
+// AllEventKinds lists every event kind, in lifecycle order.
+var AllEventKinds = []EventKind{
+	KindRunStarted,
+	KindGameScheduled,
+	KindGameSkipped,
+	KindTaskFailed,
+	KindScheduleChanged,
+	KindRunCompleted,
+	KindRunAborted,
+}
+
+// DefaultEventKinds are the events a sender receives when no subscription is
+// configured: the run summary and abort alerts.
+var DefaultEventKinds = []EventKind{KindRunCompleted, KindRunAborted}
+
+// FailureEventKinds are the events describing something that went wrong.
+var FailureEventKinds = []EventKind{KindTaskFailed, KindRunAborted}
+
+// Event is a typed notification event.
+type Event interface {
+	// Kind returns the type of the event.
+	Kind() EventKind
+}
+
+// RunStarted is emitted when a scheduling run begins.
+type RunStarted struct {
+	Date     string // Date being scheduled (YYYY-MM-DD)
+	TestMode bool
+}
+
+// GameScheduled is emitted when a task is created for a game.
+type GameScheduled struct {
+	Game GameInfo
+}
+
+// GameSkipped is emitted when a game is deliberately not scheduled.
+type GameSkipped struct {
+	Game GameInfo
+}
+
+// TaskFailed is emitted when creating a game's task fails.
+type TaskFailed struct {
+	Game GameInfo
+	Err  error
+}
+
+// RunCompleted is emitted once all games have been processed. Games holds
+// every processed game, with its status and reason.
+type RunCompleted struct {
+	Games []GameInfo
+}
+
+// RunAborted is emitted when a run stops before processing games, for
+// example because the NHL API could not be reached.
+type RunAborted struct {
+	Err error
+}
+
+// ScheduleChanged is emitted when a game's start time differs from the one
+// it was previously scheduled with.
+type ScheduleChanged struct {
+	Game              GameInfo
+	PreviousStartTime string
+}
+
+// Kind returns KindRunStarted.
+func (RunStarted) Kind() EventKind { return KindRunStarted }
+
+// Kind returns KindGameScheduled.
+func (GameScheduled) Kind() EventKind { return KindGameScheduled }
+
+// Kind returns KindGameSkipped.
+func (GameSkipped) Kind() EventKind { return KindGameSkipped }
+
+// Kind returns KindTaskFailed.
+func (TaskFailed) Kind() EventKind { return KindTaskFailed }
+
+// Kind returns KindRunCompleted.
+func (RunCompleted) Kind() EventKind { return KindRunCompleted }
+
+// Kind returns KindRunAborted.
+func (RunAborted) Kind() EventKind { return KindRunAborted }
+
+// Kind returns KindScheduleChanged.
+func (ScheduleChanged) Kind() EventKind { return KindScheduleChanged }
+
+// Notifier defines the interface for delivering typed notification events.
+type Notifier interface {
+	// Notify delivers an event.
+	// Returns an error if the event could not be delivered.
+	Notify(ctx context.Context, event Event) error
+}
+
+// ParseEventKinds parses a comma-separated list of event kinds, such as
+// "task_failed,run_aborted". The special value "all" selects every kind and
+// "failures" selects FailureEventKinds.
+func ParseEventKinds(s string) ([]EventKind, error) {
+	var kinds []EventKind
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		switch part {
+		case "":
+			continue
+		case "all":
+			kinds = append(kinds, AllEventKinds...)
+			continue
+		case "failures":
+			kinds = append(kinds, FailureEventKinds...)
+			continue
+		}
+
+		found := false
+		for _, kind := range AllEventKinds {
+			if EventKind(part) == kind {
+				kinds = append(kinds, kind)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown notification event %q", part)
+		}
+	}
+
+	return kinds, nil
+}
+
+// subscription is a Notifier that forwards only the event kinds it is subscribed to.
+type subscription struct {
+	next  Notifier
+	kinds map[EventKind]bool
+}
+
+// Subscribe returns a Notifier that forwards only events of the given kinds
+// to n and silently drops all others. With no kinds, DefaultEventKinds is used.
+func Subscribe(n Notifier, kinds ...EventKind) Notifier {
+	if len(kinds) == 0 {
+		kinds = DefaultEventKinds
+	}
+
+	s := &subscription{next: n, kinds: make(map[EventKind]bool)}
+	for _, kind := range kinds {
+		s.kinds[kind] = true
+	}
+	return s
+}
+
+// Notify forwards the event if its kind is subscribed.
+func (s *subscription) Notify(ctx context.Context, event Event) error {
+	if !s.kinds[event.Kind()] {
+		return nil
+	}
+	return s.next.Notify(ctx, event)
+}
+
+// MultiNotifier fans each event out to several notifiers.
+type MultiNotifier []Notifier
+
+// Notify delivers the event to every notifier, even if some fail, and
+// returns the combined errors.
+func (m MultiNotifier) Notify(ctx context.Context, event Event) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SenderNotifier adapts a Sender to the Notifier interface. Run summaries
+// and abort alerts keep their existing Sender rendering; per-game events
+// are sent as plain text messages.
+type SenderNotifier struct {
+	sender   ContextSender
+	messages *Messages
+	location *time.Location
+}
+
+// SenderNotifierOptions customizes how a SenderNotifier renders text messages.
+type SenderNotifierOptions struct {
+	// Messages is the message catalog used to render text. Nil uses English.
+	Messages *Messages
+
+	// Location is the time zone start times are shown in, matching the local
+	// time in Discord embeds. Nil shows the API's UTC start times.
+	Location *time.Location
+}
+
+// NewSenderNotifier creates a Notifier backed by the given Sender, rendering
+// text messages in English.
+func NewSenderNotifier(sender Sender) *SenderNotifier {
+	return NewSenderNotifierWithOptions(sender, SenderNotifierOptions{})
+}
+
+// NewLocalizedSenderNotifier creates a Notifier backed by the given Sender,
+// rendering text messages from the given catalog.
+func NewLocalizedSenderNotifier(sender Sender, messages *Messages) *SenderNotifier {
+	return NewSenderNotifierWithOptions(sender, SenderNotifierOptions{Messages: messages})
+}
+
+// NewSenderNotifierWithOptions creates a Notifier backed by the given Sender
+// with the given rendering options.
+func NewSenderNotifierWithOptions(sender Sender, options SenderNotifierOptions) *SenderNotifier {
+	messages := options.Messages
+	if messages == nil {
+		messages = defaultMessages()
+	}
+	return &SenderNotifier{sender: WithContext(sender), messages: messages, location: options.Location}
+}
+
+// Notify renders the event with the underlying Sender.
+func (s *SenderNotifier) Notify(ctx context.Context, event Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m := s.messages
+	switch e := event.(type) {
+	case RunStarted:
+		message := fmt.Sprintf(m.RunStarted, e.Date)
+		if e.TestMode {
+			message += m.TestModeSuffix
+		}
+		return s.sender.SendContext(ctx, message)
+	case GameScheduled:
+		return s.sender.SendContext(ctx, fmt.Sprintf(m.GameScheduled, matchup(e.Game), formatStartTime(m, s.location, e.Game.StartTime)))
+	case GameSkipped:
+		return s.sender.SendContext(ctx, fmt.Sprintf(m.GameSkipped, matchup(e.Game), m.reason(e.Game.Reason)))
+	case TaskFailed:
+		return s.sender.SendContext(ctx, fmt.Sprintf(m.TaskFailed, matchup(e.Game), e.Err))
+	case ScheduleChanged:
+		return s.sender.SendContext(ctx, fmt.Sprintf(m.ScheduleChanged, matchup(e.Game),
+			formatStartTime(m, s.location, e.PreviousStartTime), formatStartTime(m, s.location, e.Game.StartTime)))
+	case RunCompleted:
+		return s.sender.SendScheduleSummaryContext(ctx, e.Games)
+	case RunAborted:
+		return s.sender.SendAlertContext(ctx, m.RunAbortedTitle, e.Err.Error())
+	default:
+		return fmt.Errorf("unsupported notification event %q", event.Kind())
+	}
+}
+
+// matchup returns the "AWAY @ HOME" abbreviation pair for a game.
+func matchup(game GameInfo) string {
+	return fmt.Sprintf("%s @ %s", game.AwayTeam, game.HomeTeam)
+}
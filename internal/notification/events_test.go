@@ -0,0 +1,246 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingSender is a Sender that records every call for inspection.
+type recordingSender struct {
+	messages  []string
+	summaries [][]GameInfo
+	alerts    []string
+	err       error
+}
+
+func (r *recordingSender) Send(message string) error {
+	r.messages = append(r.messages, message)
+	return r.err
+}
+
+func (r *recordingSender) SendScheduleSummary(games []GameInfo) error {
+	r.summaries = append(r.summaries, games)
+	return r.err
+}
+
+func (r *recordingSender) SendAlert(title, message string) error {
+	r.alerts = append(r.alerts, title+": "+message)
+	return r.err
+}
+
+func (r *recordingSender) IsEnabled() bool {
+	return true
+}
+
+// recordingNotifier is a Notifier that records every event it receives.
+type recordingNotifier struct {
+	events []Event
+	err    error
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, event Event) error {
+	r.events = append(r.events, event)
+	return r.err
+}
+
+// --- ParseEventKinds ---
+
+func TestParseEventKinds(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []EventKind
+	}{
+		{"", nil},
+		{"run_completed", []EventKind{KindRunCompleted}},
+		{" Task_Failed , run_aborted ", []EventKind{KindTaskFailed, KindRunAborted}},
+		{"failures", FailureEventKinds},
+		{"all", AllEventKinds},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEventKinds(tt.input)
+		if err != nil {
+			t.Errorf("ParseEventKinds(%q) returned error: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseEventKinds(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseEventKinds_Unknown(t *testing.T) {
+	_, err := ParseEventKinds("run_completed,bogus")
+	if err == nil {
+		t.Fatal("ParseEventKinds() with unknown kind returned nil error")
+	}
+	if !strings.Contains(err.Error(), "bogus") {
+		t.Errorf("error = %q, want it to name the unknown kind", err.Error())
+	}
+}
+
+// --- Subscribe ---
+
+func TestSubscribe_FiltersKinds(t *testing.T) {
+	rec := &recordingNotifier{}
+	n := Subscribe(rec, FailureEventKinds...)
+
+	ctx := context.Background()
+	events := []Event{
+		RunStarted{Date: "2024-01-01"},
+		GameScheduled{},
+		TaskFailed{Err: errors.New("boom")},
+		RunCompleted{},
+		RunAborted{Err: errors.New("down")},
+	}
+	for _, event := range events {
+		if err := n.Notify(ctx, event); err != nil {
+			t.Fatalf("Notify(%s) returned error: %v", event.Kind(), err)
+		}
+	}
+
+	if len(rec.events) != 2 {
+		t.Fatalf("forwarded %d events, want 2", len(rec.events))
+	}
+	if rec.events[0].Kind() != KindTaskFailed || rec.events[1].Kind() != KindRunAborted {
+		t.Errorf("forwarded kinds = %s, %s, want task_failed, run_aborted", rec.events[0].Kind(), rec.events[1].Kind())
+	}
+}
+
+func TestSubscribe_DefaultKinds(t *testing.T) {
+	rec := &recordingNotifier{}
+	n := Subscribe(rec)
+
+	ctx := context.Background()
+	n.Notify(ctx, GameScheduled{})
+	n.Notify(ctx, RunCompleted{})
+
+	if len(rec.events) != 1 || rec.events[0].Kind() != KindRunCompleted {
+		t.Errorf("forwarded events = %v, want only run_completed", rec.events)
+	}
+}
+
+// --- MultiNotifier ---
+
+func TestMultiNotifier_DeliversToAllAndJoinsErrors(t *testing.T) {
+	first := &recordingNotifier{err: errors.New("first failed")}
+	second := &recordingNotifier{}
+	third := &recordingNotifier{err: errors.New("third failed")}
+
+	err := MultiNotifier{first, second, third}.Notify(context.Background(), RunCompleted{})
+	if err == nil {
+		t.Fatal("Notify() returned nil error, want joined errors")
+	}
+	for _, want := range []string{"first failed", "third failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error = %q, want it to contain %q", err.Error(), want)
+		}
+	}
+	if len(second.events) != 1 {
+		t.Error("second notifier did not receive the event after first failed")
+	}
+}
+
+// --- SenderNotifier adapter ---
+
+func TestSenderNotifier_RunCompletedSendsSummary(t *testing.T) {
+	sender := &recordingSender{}
+	n := NewSenderNotifier(sender)
+
+	games := []GameInfo{{ID: "1", HomeTeam: "BOS", AwayTeam: "DAL"}}
+	if err := n.Notify(context.Background(), RunCompleted{Games: games}); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+
+	if len(sender.summaries) != 1 || !reflect.DeepEqual(sender.summaries[0], games) {
+		t.Errorf("summaries = %v, want one summary of %v", sender.summaries, games)
+	}
+}
+
+func TestSenderNotifier_RunAbortedSendsAlert(t *testing.T) {
+	sender := &recordingSender{}
+	n := NewSenderNotifier(sender)
+
+	if err := n.Notify(context.Background(), RunAborted{Err: errors.New("NHL API returned status: 503")}); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+
+	if len(sender.alerts) != 1 || !strings.Contains(sender.alerts[0], "503") {
+		t.Errorf("alerts = %v, want one alert with the error", sender.alerts)
+	}
+}
+
+func TestSenderNotifier_GameEventsSendText(t *testing.T) {
+	sender := &recordingSender{}
+	n := NewSenderNotifier(sender)
+	game := GameInfo{HomeTeam: "BOS", AwayTeam: "DAL", StartTime: "2024-01-01T19:00:00Z", Reason: "already started"}
+
+	ctx := context.Background()
+	n.Notify(ctx, GameScheduled{Game: game})
+	n.Notify(ctx, GameSkipped{Game: game})
+	n.Notify(ctx, TaskFailed{Game: game, Err: errors.New("queue not found")})
+	n.Notify(ctx, ScheduleChanged{Game: game, PreviousStartTime: "2024-01-01T18:00:00Z"})
+
+	wants := []string{"Scheduled DAL @ BOS", "already started", "queue not found", "2024-01-01T18:00:00Z"}
+	if len(sender.messages) != len(wants) {
+		t.Fatalf("messages = %v, want %d messages", sender.messages, len(wants))
+	}
+	for i, want := range wants {
+		if !strings.Contains(sender.messages[i], want) {
+			t.Errorf("message %d = %q, want it to contain %q", i, sender.messages[i], want)
+		}
+	}
+}
+
+func TestSenderNotifier_GameEventsUseLocation(t *testing.T) {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	sender := &recordingSender{}
+	n := NewSenderNotifierWithOptions(sender, SenderNotifierOptions{Location: chicago})
+	game := GameInfo{ID: "1", HomeTeam: "BOS", AwayTeam: "DAL", StartTime: "2024-01-01T19:00:00Z"}
+
+	ctx := context.Background()
+	n.Notify(ctx, GameScheduled{Game: game})
+	n.Notify(ctx, ScheduleChanged{Game: game, PreviousStartTime: "2024-01-01T18:00:00Z"})
+
+	wants := []string{
+		"Scheduled DAL @ BOS at Mon Jan 1, 1:00 PM CST",
+		"DAL @ BOS start time changed from Mon Jan 1, 12:00 PM CST to Mon Jan 1, 1:00 PM CST",
+	}
+	if !reflect.DeepEqual(sender.messages, wants) {
+		t.Errorf("messages = %q, want %q", sender.messages, wants)
+	}
+
+	// The text matches the local time shown in the game's Discord embed
+	embed := gameEmbed(defaultMessages(), chicago, game)
+	if local := embed.Fields[1].Value; !strings.HasSuffix(sender.messages[0], local) {
+		t.Errorf("text message %q does not show the embed's local time %q", sender.messages[0], local)
+	}
+}
+
+func TestSenderNotifier_CanceledContext(t *testing.T) {
+	sender := &recordingSender{}
+	n := NewSenderNotifier(sender)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := n.Notify(ctx, RunCompleted{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Notify() error = %v, want context.Canceled", err)
+	}
+	if len(sender.summaries) != 0 {
+		t.Error("summary sent despite canceled context")
+	}
+}
+
+func TestSenderNotifier_WrapsDiscordSender(t *testing.T) {
+	// Compile-time check that the adapter satisfies Notifier for any Sender
+	var _ Notifier = NewSenderNotifier(&DiscordSender{})
+	var _ Notifier = NewSenderNotifier(NewNoOpSender())
+}
@@ -2,10 +2,13 @@ package notification
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 // --- NoOpSender tests ---
@@ -32,6 +35,13 @@ func TestNoOpSender_SendScheduleSummary(t *testing.T) {
 	}
 }
 
+func TestNoOpSender_SendAlert(t *testing.T) {
+	s := NewNoOpSender()
+	if err := s.SendAlert("title", "message"); err != nil {
+		t.Errorf("NoOpSender.SendAlert() returned error: %v", err)
+	}
+}
+
 // --- NewDiscordSender constructor tests ---
 
 func TestNewDiscordSender_EmptyURL(t *testing.T) {
@@ -157,11 +167,15 @@ func TestDiscordSender_SendScheduleSummary_OneGame(t *testing.T) {
 
 	games := []GameInfo{
 		{
-			ID:        "2024020001",
-			GameDate:  "2024-11-15",
-			StartTime: "2024-11-15T00:00:00Z",
-			HomeTeam:  "BOS",
-			AwayTeam:  "DAL",
+			ID:           "2024020001",
+			GameDate:     "2024-11-15",
+			StartTime:    "2024-11-15T00:00:00Z",
+			HomeTeam:     "BOS",
+			AwayTeam:     "DAL",
+			HomeTeamID:   1,
+			AwayTeamID:   25,
+			HomeTeamName: "Boston Bruins",
+			AwayTeamName: "Dallas Stars",
 		},
 	}
 
@@ -170,34 +184,45 @@ func TestDiscordSender_SendScheduleSummary_OneGame(t *testing.T) {
 		t.Fatalf("SendScheduleSummary() returned error: %v", err)
 	}
 
-	if len(received.Embeds) != 1 {
-		t.Fatalf("embed count = %d, want 1", len(received.Embeds))
+	// Header embed followed by one embed per game
+	if len(received.Embeds) != 2 {
+		t.Fatalf("embed count = %d, want 2", len(received.Embeds))
 	}
 
-	embed := received.Embeds[0]
+	header := received.Embeds[0]
 
 	// Singular "game" for 1 game
 	expectedTitle := "NHL Game Schedule (1 game scheduled)"
-	if embed.Title != expectedTitle {
-		t.Errorf("title = %q, want %q", embed.Title, expectedTitle)
+	if header.Title != expectedTitle {
+		t.Errorf("title = %q, want %q", header.Title, expectedTitle)
 	}
-
-	if embed.Color != 3066993 {
-		t.Errorf("color = %d, want %d (green)", embed.Color, 3066993)
+	if header.Color != 3066993 {
+		t.Errorf("color = %d, want %d (green)", header.Color, 3066993)
+	}
+	if header.Timestamp == "" {
+		t.Error("timestamp is empty, want RFC3339 value")
 	}
 
-	// Verify game details appear in description
-	if !strings.Contains(embed.Description, "DAL @ BOS") {
-		t.Errorf("description missing matchup, got: %q", embed.Description)
+	game := received.Embeds[1]
+	if game.Title != "Dallas Stars @ Boston Bruins" {
+		t.Errorf("game title = %q, want full team names", game.Title)
 	}
-	if !strings.Contains(embed.Description, "2024-11-15") {
-		t.Errorf("description missing game date, got: %q", embed.Description)
+	if game.Color != 0xFFB81C {
+		t.Errorf("game color = %#x, want %#x (Boston)", game.Color, 0xFFB81C)
 	}
-	if !strings.Contains(embed.Description, "2024-11-15T00:00:00Z") {
-		t.Errorf("description missing start time, got: %q", embed.Description)
+	if game.Thumbnail == nil || game.Thumbnail.URL != "https://assets.nhle.com/logos/nhl/svg/BOS_light.svg" {
+		t.Errorf("game thumbnail = %+v, want home team logo", game.Thumbnail)
 	}
-	if embed.Timestamp == "" {
-		t.Error("timestamp is empty, want RFC3339 value")
+	if game.Footer == nil || !strings.Contains(game.Footer.Text, "DAL @ BOS") || !strings.Contains(game.Footer.Text, "2024020001") {
+		t.Errorf("game footer = %+v, want matchup and game ID", game.Footer)
+	}
+
+	// 2024-11-15T00:00:00Z is unix 1731628800
+	if len(game.Fields) != 1 {
+		t.Fatalf("game field count = %d, want 1", len(game.Fields))
+	}
+	if want := "<t:1731628800:F> (<t:1731628800:R>)"; game.Fields[0].Value != want {
+		t.Errorf("start field = %q, want %q", game.Fields[0].Value, want)
 	}
 }
 
@@ -239,29 +264,58 @@ func TestDiscordSender_SendScheduleSummary_MultipleGames(t *testing.T) {
 		t.Fatalf("SendScheduleSummary() returned error: %v", err)
 	}
 
-	embed := received.Embeds[0]
-
 	// Plural "games" for multiple
 	expectedTitle := "NHL Game Schedule (3 games scheduled)"
-	if embed.Title != expectedTitle {
-		t.Errorf("title = %q, want %q", embed.Title, expectedTitle)
+	if received.Embeds[0].Title != expectedTitle {
+		t.Errorf("title = %q, want %q", received.Embeds[0].Title, expectedTitle)
+	}
+
+	if len(received.Embeds) != 4 {
+		t.Fatalf("embed count = %d, want 4", len(received.Embeds))
 	}
 
-	// All matchups present
-	for _, matchup := range []string{"DAL @ BOS", "CHI @ NYR", "SEA @ LAK"} {
-		if !strings.Contains(embed.Description, matchup) {
-			t.Errorf("description missing matchup %q, got:\n%s", matchup, embed.Description)
+	// Without full names, game embeds fall back to abbreviations
+	for i, matchup := range []string{"DAL @ BOS", "CHI @ NYR", "SEA @ LAK"} {
+		if received.Embeds[i+1].Title != matchup {
+			t.Errorf("embed %d title = %q, want %q", i+1, received.Embeds[i+1].Title, matchup)
 		}
 	}
 
-	// All start times present
-	for _, startTime := range []string{"19:00:00Z", "20:00:00Z", "22:30:00Z"} {
-		if !strings.Contains(embed.Description, startTime) {
-			t.Errorf("description missing start time %q, got:\n%s", startTime, embed.Description)
+	// All start times rendered as Discord timestamps
+	for i, startTime := range []string{"2024-11-15T19:00:00Z", "2024-11-15T20:00:00Z", "2024-11-15T22:30:00Z"} {
+		parsed, _ := time.Parse(time.RFC3339, startTime)
+		want := fmt.Sprintf("<t:%d:F>", parsed.Unix())
+		if !strings.Contains(received.Embeds[i+1].Fields[0].Value, want) {
+			t.Errorf("embed %d start = %q, want %q", i+1, received.Embeds[i+1].Fields[0].Value, want)
 		}
 	}
 }
 
+func TestDiscordSender_SendScheduleSummary_UnknownTeamAndBadStartTime(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	games := []GameInfo{{ID: "1", GameDate: "2024-01-01", StartTime: "TBD", HomeTeam: "XXX", AwayTeam: "DAL", HomeTeamID: 999}}
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	game := received.Embeds[1]
+	if game.Color != defaultTeamColor {
+		t.Errorf("color = %d, want default %d", game.Color, defaultTeamColor)
+	}
+	if game.Fields[0].Value != "2024-01-01 at TBD" {
+		t.Errorf("start field = %q, want raw date and start time", game.Fields[0].Value)
+	}
+}
+
 // --- HTTP response handling tests ---
 
 func TestDiscordSender_Send_HTTP200(t *testing.T) {
@@ -392,8 +446,8 @@ func TestDiscordSender_SendScheduleSummary_PayloadIsValidJSON(t *testing.T) {
 	if err := json.Unmarshal(rawBody, &msg); err != nil {
 		t.Fatalf("failed to unmarshal payload: %v", err)
 	}
-	if len(msg.Embeds) != 1 {
-		t.Errorf("expected 1 embed, got %d", len(msg.Embeds))
+	if len(msg.Embeds) != 2 {
+		t.Errorf("expected 2 embeds, got %d", len(msg.Embeds))
 	}
 }
 
@@ -447,3 +501,450 @@ func TestDiscordSender_SendScheduleSummary_TwoGames(t *testing.T) {
 		t.Errorf("title = %q, want %q", received.Embeds[0].Title, expected)
 	}
 }
+
+// --- Pagination ---
+
+func TestDiscordSender_SendScheduleSummary_SortsByStartTime(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	games := []GameInfo{
+		{ID: "2", GameDate: "2024-01-01", StartTime: "2024-01-02T01:00:00Z", HomeTeam: "NYR", AwayTeam: "CHI"},
+		{ID: "1", GameDate: "2024-01-01", StartTime: "2024-01-01T19:00:00Z", HomeTeam: "BOS", AwayTeam: "DAL"},
+	}
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	if received.Embeds[1].Title != "DAL @ BOS" || received.Embeds[2].Title != "CHI @ NYR" {
+		t.Errorf("games not in start-time order: %q, %q", received.Embeds[1].Title, received.Embeds[2].Title)
+	}
+}
+
+func TestDiscordSender_SendScheduleSummary_SplitsLargeSummary(t *testing.T) {
+	var messages []discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Fatalf("failed to decode request body: %v", err)
+		}
+		messages = append(messages, msg)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// A full 16-game night plus a few more needs more than one message
+	var games []GameInfo
+	start := time.Date(2024, 1, 1, 17, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		games = append(games, GameInfo{
+			ID:        fmt.Sprintf("20240200%02d", i),
+			GameDate:  "2024-01-01",
+			StartTime: start.Add(time.Duration(i) * time.Minute).Format(time.RFC3339),
+			HomeTeam:  "BOS",
+			AwayTeam:  fmt.Sprintf("T%02d", i),
+		})
+	}
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	if len(messages) != 3 {
+		t.Fatalf("message count = %d, want 3", len(messages))
+	}
+
+	var titles []string
+	for i, msg := range messages {
+		if len(msg.Embeds) > maxEmbedsPerMessage {
+			t.Errorf("message %d has %d embeds, want at most %d", i, len(msg.Embeds), maxEmbedsPerMessage)
+		}
+		total := 0
+		for _, embed := range msg.Embeds {
+			total += embedLength(embed)
+		}
+		if total > maxMessageEmbedLength {
+			t.Errorf("message %d embed length = %d, want at most %d", i, total, maxMessageEmbedLength)
+		}
+
+		want := fmt.Sprintf("NHL Game Schedule (20 games scheduled) %d/%d", i+1, len(messages))
+		if msg.Embeds[0].Title != want {
+			t.Errorf("message %d header title = %q, want %q", i, msg.Embeds[0].Title, want)
+		}
+		for _, embed := range msg.Embeds[1:] {
+			titles = append(titles, embed.Title)
+		}
+	}
+
+	// Every game appears exactly once and in order
+	if len(titles) != len(games) {
+		t.Fatalf("game embed count = %d, want %d", len(titles), len(games))
+	}
+	for i, title := range titles {
+		if want := fmt.Sprintf("T%02d @ BOS", i); title != want {
+			t.Errorf("game embed %d title = %q, want %q", i, title, want)
+		}
+	}
+}
+
+func TestPackEmbeds_RespectsLimits(t *testing.T) {
+	embeds := make([]discordEmbed, 25)
+	for i := range embeds {
+		embeds[i] = discordEmbed{Title: "t", Description: "d"}
+	}
+
+	chunks := packEmbeds(embeds, maxEmbedsPerMessage, maxMessageEmbedLength)
+	if len(chunks) != 3 {
+		t.Fatalf("chunk count = %d, want 3", len(chunks))
+	}
+	if len(chunks[0]) != maxEmbedsPerMessage || len(chunks[2]) != 5 {
+		t.Errorf("embed distribution = %d/%d/%d, want 10/10/5",
+			len(chunks[0]), len(chunks[1]), len(chunks[2]))
+	}
+
+	// Length budget splits before the count limit is reached
+	chunks = packEmbeds(embeds, maxEmbedsPerMessage, 5)
+	if len(chunks) != 13 {
+		t.Errorf("chunk count with length budget = %d, want 13", len(chunks))
+	}
+}
+
+// --- Failure reporting ---
+
+func TestDiscordSender_SendScheduleSummary_PartialFailure(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	games := []GameInfo{
+		{ID: "1", StartTime: "2024-01-01T19:00:00Z", HomeTeam: "BOS", AwayTeam: "DAL"},
+		{ID: "2", StartTime: "2024-01-01T20:00:00Z", HomeTeam: "NYR", AwayTeam: "CHI", Status: GameStatusSkipped, Reason: "game already started"},
+		{ID: "3", StartTime: "2024-01-01T21:00:00Z", HomeTeam: "LAK", AwayTeam: "SEA", Status: GameStatusFailed, Reason: "queue not found"},
+	}
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	header := received.Embeds[0]
+	if want := "NHL Game Schedule (1 game scheduled, 1 skipped, 1 failed)"; header.Title != want {
+		t.Errorf("title = %q, want %q", header.Title, want)
+	}
+	if header.Color != colorOrange {
+		t.Errorf("color = %d, want %d (orange)", header.Color, colorOrange)
+	}
+
+	if len(received.Embeds[1].Fields) != 1 {
+		t.Errorf("scheduled game field count = %d, want 1", len(received.Embeds[1].Fields))
+	}
+
+	skipped := received.Embeds[2].Fields
+	if len(skipped) != 2 || skipped[1].Name != "Skipped" || skipped[1].Value != "game already started" {
+		t.Errorf("skipped game fields = %+v, want Skipped reason", skipped)
+	}
+
+	failed := received.Embeds[3]
+	if len(failed.Fields) != 2 || failed.Fields[1].Name != "Failed" || failed.Fields[1].Value != "queue not found" {
+		t.Errorf("failed game fields = %+v, want Failed reason", failed.Fields)
+	}
+	if failed.Color != colorRed {
+		t.Errorf("failed game color = %d, want %d (red)", failed.Color, colorRed)
+	}
+}
+
+func TestDiscordSender_SendScheduleSummary_TruncatesLongReason(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// A wrapped gRPC error can run well past Discord's 1024-character field limit
+	reason := "rpc error: code = Internal desc = " + strings.Repeat("é", 2000)
+	games := []GameInfo{
+		{ID: "1", StartTime: "2024-01-01T19:00:00Z", HomeTeam: "BOS", AwayTeam: "DAL", Status: GameStatusFailed, Reason: reason},
+	}
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	field := received.Embeds[1].Fields[1]
+	if n := utf8.RuneCountInString(field.Value); n != maxFieldValueLength {
+		t.Errorf("failure reason is %d characters, want it truncated to %d", n, maxFieldValueLength)
+	}
+	if !strings.HasPrefix(field.Value, "rpc error:") || !strings.HasSuffix(field.Value, "…") {
+		t.Errorf("failure reason = %q..., want the start of the reason and an ellipsis", field.Value[:40])
+	}
+}
+
+func TestDiscordSender_TruncatesLongTextAndAlerts(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = discordMessage{}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	long := strings.Repeat("x", 5000)
+	s := NewDiscordSender(server.URL)
+
+	if err := s.Send(long); err != nil {
+		t.Fatalf("Send() returned error: %v", err)
+	}
+	if n := utf8.RuneCountInString(received.Content); n != maxContentLength {
+		t.Errorf("content is %d characters, want %d", n, maxContentLength)
+	}
+
+	if err := s.SendAlert(long, long); err != nil {
+		t.Fatalf("SendAlert() returned error: %v", err)
+	}
+	alert := received.Embeds[0]
+	if n := utf8.RuneCountInString(alert.Title); n != maxTitleLength {
+		t.Errorf("alert title is %d characters, want %d", n, maxTitleLength)
+	}
+	if n := utf8.RuneCountInString(alert.Description); n != maxDescriptionLength {
+		t.Errorf("alert description is %d characters, want %d", n, maxDescriptionLength)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"short", 10, "short"},
+		{"exactly10!", 10, "exactly10!"},
+		{"eleven char", 10, "eleven ch…"},
+		{"ééééé", 3, "éé…"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.max); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestDiscordSender_SendScheduleSummary_TotalFailure(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	games := []GameInfo{
+		{ID: "1", StartTime: "2024-01-01T19:00:00Z", HomeTeam: "BOS", AwayTeam: "DAL", Status: GameStatusFailed},
+		{ID: "2", StartTime: "2024-01-01T20:00:00Z", HomeTeam: "NYR", AwayTeam: "CHI", Status: GameStatusFailed},
+	}
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	header := received.Embeds[0]
+	if want := "NHL Game Schedule (0 games scheduled, 2 failed)"; header.Title != want {
+		t.Errorf("title = %q, want %q", header.Title, want)
+	}
+	if header.Color != colorRed {
+		t.Errorf("color = %d, want %d (red)", header.Color, colorRed)
+	}
+	if v := received.Embeds[1].Fields[1].Value; v != "No reason given" {
+		t.Errorf("failed reason = %q, want placeholder", v)
+	}
+}
+
+func TestDiscordSender_SendScheduleSummary_UnchangedGamesOnlyCounted(t *testing.T) {
+	var messages []discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := NewDiscordSender(server.URL)
+	err := s.SendScheduleSummary([]GameInfo{
+		{ID: "1", StartTime: "2024-01-01T19:00:00Z", HomeTeam: "BOS", AwayTeam: "DAL"},
+		{ID: "2", StartTime: "2024-01-01T20:00:00Z", HomeTeam: "NYR", AwayTeam: "CHI", Status: GameStatusUnchanged},
+	})
+	if err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+	if len(messages) != 1 || len(messages[0].Embeds) != 2 {
+		t.Fatalf("messages = %+v, want one message with a header and the scheduled game", messages)
+	}
+	if want := "NHL Game Schedule (1 game scheduled, 1 unchanged)"; messages[0].Embeds[0].Title != want {
+		t.Errorf("title = %q, want %q", messages[0].Embeds[0].Title, want)
+	}
+
+	// A run where nothing changed still reports itself with the header alone
+	messages = nil
+	err = s.SendScheduleSummary([]GameInfo{
+		{ID: "2", StartTime: "2024-01-01T20:00:00Z", HomeTeam: "NYR", AwayTeam: "CHI", Status: GameStatusUnchanged},
+	})
+	if err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+	if len(messages) != 1 || len(messages[0].Embeds) != 1 {
+		t.Fatalf("messages = %+v, want only the header", messages)
+	}
+	header := messages[0].Embeds[0]
+	if want := "NHL Game Schedule (0 games scheduled, 1 unchanged)"; header.Title != want {
+		t.Errorf("title = %q, want %q", header.Title, want)
+	}
+	if header.Color != colorGreen {
+		t.Errorf("color = %d, want %d (green)", header.Color, colorGreen)
+	}
+}
+
+func TestDiscordSender_SendAlert(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := NewDiscordSender(server.URL)
+	if err := s.SendAlert("Run aborted", "NHL API returned status: 503"); err != nil {
+		t.Fatalf("SendAlert() returned error: %v", err)
+	}
+
+	if len(received.Embeds) != 1 {
+		t.Fatalf("embed count = %d, want 1", len(received.Embeds))
+	}
+	embed := received.Embeds[0]
+	if embed.Title != "Run aborted" || embed.Description != "NHL API returned status: 503" {
+		t.Errorf("embed = %+v, want alert title and message", embed)
+	}
+	if embed.Color != colorRed {
+		t.Errorf("color = %d, want %d (red)", embed.Color, colorRed)
+	}
+}
+
+// --- Role mentions ---
+
+func TestDiscordSender_SendScheduleSummary_MentionsRole(t *testing.T) {
+	var messages []discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg discordMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		messages = append(messages, msg)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	var games []GameInfo
+	for i := 0; i < 12; i++ {
+		games = append(games, GameInfo{ID: fmt.Sprint(i), StartTime: "2024-01-01T19:00:00Z", HomeTeam: "DAL", AwayTeam: "CHI"})
+	}
+
+	s := NewDiscordSenderWithOptions(server.URL, DiscordOptions{MentionRoleID: "123456"})
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	if len(messages) != 2 {
+		t.Fatalf("message count = %d, want 2", len(messages))
+	}
+	if messages[0].Content != "<@&123456>" {
+		t.Errorf("first message content = %q, want role mention", messages[0].Content)
+	}
+	if messages[0].AllowedMentions == nil || len(messages[0].AllowedMentions.Roles) != 1 || messages[0].AllowedMentions.Roles[0] != "123456" {
+		t.Errorf("allowed mentions = %+v, want only role 123456", messages[0].AllowedMentions)
+	}
+	// Only the first part pings the role
+	if messages[1].Content != "" {
+		t.Errorf("second message content = %q, want empty", messages[1].Content)
+	}
+}
+
+func TestDiscordSender_SendAlert_MentionsRole(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	s := NewDiscordSenderWithOptions(server.URL, DiscordOptions{MentionRoleID: "42"})
+	if err := s.SendAlert("Run aborted", "down"); err != nil {
+		t.Fatalf("SendAlert() returned error: %v", err)
+	}
+
+	if received.Content != "<@&42>" {
+		t.Errorf("content = %q, want role mention", received.Content)
+	}
+}
+
+// --- Time zones ---
+
+func TestDiscordSender_SendScheduleSummary_LocalAndVenueTimes(t *testing.T) {
+	var received discordMessage
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	games := []GameInfo{
+		{ID: "1", StartTime: "2024-11-16T00:00:00Z", HomeTeam: "BOS", AwayTeam: "DAL", VenueTimezone: "America/New_York"},
+		{ID: "2", StartTime: "2024-11-16T01:00:00Z", HomeTeam: "XXX", AwayTeam: "DAL", VenueTimezone: "Not/AZone"},
+	}
+
+	s := NewDiscordSenderWithOptions(server.URL, DiscordOptions{Location: chicago})
+	if err := s.SendScheduleSummary(games); err != nil {
+		t.Fatalf("SendScheduleSummary() returned error: %v", err)
+	}
+
+	fields := received.Embeds[1].Fields
+	if len(fields) != 3 {
+		t.Fatalf("field count = %d, want 3 (start, local, venue)", len(fields))
+	}
+	if fields[1].Name != "Local time" || fields[1].Value != "Fri Nov 15, 6:00 PM CST" {
+		t.Errorf("local time field = %+v, want Chicago time", fields[1])
+	}
+	if fields[2].Name != "Venue time" || fields[2].Value != "Fri Nov 15, 7:00 PM EST" {
+		t.Errorf("venue time field = %+v, want New York time", fields[2])
+	}
+
+	// An unknown venue zone only omits the venue time
+	if n := len(received.Embeds[2].Fields); n != 2 {
+		t.Errorf("field count with invalid venue zone = %d, want 2", n)
+	}
+}
@@ -0,0 +1,87 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+)
+
+// TeamRouter is a Notifier that routes each team's games to that team's own
+// notifier. Game events go to the notifiers of both teams playing, run
+// summaries are filtered down to each team's games, and run-level events
+// such as aborts are delivered to every team.
+type TeamRouter struct {
+	routes map[string]Notifier
+}
+
+// NewTeamRouter creates a TeamRouter from a map of team abbreviation
+// (e.g. "DAL") to the notifier for that team's channel.
+func NewTeamRouter(routes map[string]Notifier) *TeamRouter {
+	r := &TeamRouter{routes: make(map[string]Notifier)}
+	for team, n := range routes {
+		r.routes[strings.ToUpper(team)] = n
+	}
+	return r
+}
+
+// Notify routes the event to the notifiers of the teams it concerns.
+func (r *TeamRouter) Notify(ctx context.Context, event Event) error {
+	var errs []error
+
+	for _, team := range r.teams() {
+		routed, ok := r.eventForTeam(event, team)
+		if !ok {
+			continue
+		}
+		if err := r.routes[team].Notify(ctx, routed); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// eventForTeam returns the event as the given team's channel should see it,
+// or false if the event does not concern the team.
+func (r *TeamRouter) eventForTeam(event Event, team string) (Event, bool) {
+	switch e := event.(type) {
+	case GameScheduled:
+		return e, involvesTeam(e.Game, team)
+	case GameSkipped:
+		return e, involvesTeam(e.Game, team)
+	case TaskFailed:
+		return e, involvesTeam(e.Game, team)
+	case ScheduleChanged:
+		return e, involvesTeam(e.Game, team)
+	case RunCompleted:
+		var games []GameInfo
+		for _, game := range e.Games {
+			if involvesTeam(game, team) {
+				games = append(games, game)
+			}
+		}
+		// Teams without games today don't get an empty summary
+		if len(games) == 0 {
+			return nil, false
+		}
+		return RunCompleted{Games: games}, true
+	default:
+		return event, true
+	}
+}
+
+// teams returns the routed team abbreviations in a stable order.
+func (r *TeamRouter) teams() []string {
+	teams := make([]string, 0, len(r.routes))
+	for team := range r.routes {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// involvesTeam reports whether the given team plays in the game.
+func involvesTeam(game GameInfo, team string) bool {
+	return strings.EqualFold(game.HomeTeam, team) || strings.EqualFold(game.AwayTeam, team)
+}
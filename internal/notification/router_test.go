@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTeamRouter_RoutesGameEventsToBothTeams(t *testing.T) {
+	dal := &recordingNotifier{}
+	chi := &recordingNotifier{}
+	bos := &recordingNotifier{}
+	r := NewTeamRouter(map[string]Notifier{"DAL": dal, "chi": chi, "BOS": bos})
+
+	game := GameInfo{ID: "1", HomeTeam: "BOS", AwayTeam: "DAL"}
+	if err := r.Notify(context.Background(), GameScheduled{Game: game}); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+
+	if len(dal.events) != 1 || len(bos.events) != 1 {
+		t.Errorf("DAL/BOS received %d/%d events, want 1/1", len(dal.events), len(bos.events))
+	}
+	if len(chi.events) != 0 {
+		t.Errorf("CHI received %d events, want 0", len(chi.events))
+	}
+}
+
+func TestTeamRouter_FiltersSummaryPerTeam(t *testing.T) {
+	dal := &recordingNotifier{}
+	chi := &recordingNotifier{}
+	tor := &recordingNotifier{}
+	r := NewTeamRouter(map[string]Notifier{"DAL": dal, "CHI": chi, "TOR": tor})
+
+	games := []GameInfo{
+		{ID: "1", HomeTeam: "BOS", AwayTeam: "DAL"},
+		{ID: "2", HomeTeam: "CHI", AwayTeam: "NYR"},
+		{ID: "3", HomeTeam: "DAL", AwayTeam: "CHI"},
+	}
+	if err := r.Notify(context.Background(), RunCompleted{Games: games}); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+
+	gameIDs := func(n *recordingNotifier) string {
+		if len(n.events) != 1 {
+			return ""
+		}
+		var ids []string
+		for _, game := range n.events[0].(RunCompleted).Games {
+			ids = append(ids, game.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+
+	if got := gameIDs(dal); got != "1,3" {
+		t.Errorf("DAL summary games = %q, want %q", got, "1,3")
+	}
+	if got := gameIDs(chi); got != "2,3" {
+		t.Errorf("CHI summary games = %q, want %q", got, "2,3")
+	}
+	if len(tor.events) != 0 {
+		t.Errorf("TOR received %d events, want none without games", len(tor.events))
+	}
+}
+
+func TestTeamRouter_BroadcastsRunEvents(t *testing.T) {
+	dal := &recordingNotifier{}
+	chi := &recordingNotifier{}
+	r := NewTeamRouter(map[string]Notifier{"DAL": dal, "CHI": chi})
+
+	if err := r.Notify(context.Background(), RunAborted{Err: errors.New("down")}); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+
+	if len(dal.events) != 1 || len(chi.events) != 1 {
+		t.Errorf("DAL/CHI received %d/%d events, want 1/1", len(dal.events), len(chi.events))
+	}
+}
+
+func TestTeamRouter_JoinsErrors(t *testing.T) {
+	dal := &recordingNotifier{err: errors.New("dal webhook failed")}
+	chi := &recordingNotifier{}
+	r := NewTeamRouter(map[string]Notifier{"DAL": dal, "CHI": chi})
+
+	err := r.Notify(context.Background(), RunAborted{Err: errors.New("down")})
+	if err == nil || !strings.Contains(err.Error(), "dal webhook failed") {
+		t.Errorf("Notify() error = %v, want DAL webhook error", err)
+	}
+	if len(chi.events) != 1 {
+		t.Error("CHI did not receive the event after DAL failed")
+	}
+}
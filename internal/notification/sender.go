@@ -1,13 +1,38 @@
 // Package notification provides interfaces and implementations for sending notifications.
 package notification
 
+import "context"
+
+// GameStatus describes the outcome of processing a game.
+type GameStatus int
+
+const (
+	// GameStatusScheduled means a task was created for the game.
+	GameStatusScheduled GameStatus = iota
+	// GameStatusSkipped means the game was deliberately not scheduled,
+	// for example because it had already started.
+	GameStatusSkipped
+	// GameStatusFailed means scheduling the game was attempted and failed.
+	GameStatusFailed
+	// GameStatusUnchanged means the game was not scheduled again because it
+	// has not changed since an earlier run scheduled it.
+	GameStatusUnchanged
+)
+
 // GameInfo contains information about a game for notifications.
 type GameInfo struct {
-	ID        string
-	GameDate  string
-	StartTime string
-	HomeTeam  string
-	AwayTeam  string
+	ID            string
+	GameDate      string
+	StartTime     string // RFC3339, UTC
+	HomeTeam      string // Team abbreviation, e.g. "BOS"
+	AwayTeam      string // Team abbreviation, e.g. "DAL"
+	HomeTeamID    int
+	AwayTeamID    int
+	HomeTeamName  string // Full team name, e.g. "Boston Bruins"
+	AwayTeamName  string // Full team name, e.g. "Dallas Stars"
+	VenueTimezone string // IANA zone of the arena, e.g. "America/New_York"
+	Status        GameStatus
+	Reason        string // Why the game was skipped or failed
 }
 
 // Sender defines the interface for sending notifications.
@@ -23,10 +48,66 @@ type Sender interface {
 	// Returns an error if the notification could not be sent.
 	SendScheduleSummary(games []GameInfo) error
 
+	// SendAlert sends a high-visibility alert, used when a run aborts.
+	// Returns an error if the notification could not be sent.
+	SendAlert(title, message string) error
+
 	// IsEnabled returns whether the notification sender is configured and enabled.
 	IsEnabled() bool
 }
 
+// ContextSender is a Sender whose sends can be abandoned by cancelling ctx.
+// SenderNotifier sends through it so a notification in flight stops with the run.
+type ContextSender interface {
+	// SendContext sends a notification message.
+	SendContext(ctx context.Context, message string) error
+
+	// SendScheduleSummaryContext sends a summary notification of all processed games.
+	SendScheduleSummaryContext(ctx context.Context, games []GameInfo) error
+
+	// SendAlertContext sends a high-visibility alert, used when a run aborts.
+	SendAlertContext(ctx context.Context, title, message string) error
+
+	// IsEnabled returns whether the notification sender is configured and enabled.
+	IsEnabled() bool
+}
+
+// WithContext adapts sender to ContextSender. Senders that already implement
+// it are returned unchanged; others skip a send once ctx is done but cannot
+// abandon one already in flight.
+func WithContext(sender Sender) ContextSender {
+	if contextSender, ok := sender.(ContextSender); ok {
+		return contextSender
+	}
+	return contextAdapter{sender}
+}
+
+// contextAdapter wraps a plain Sender as a ContextSender.
+type contextAdapter struct {
+	Sender
+}
+
+func (a contextAdapter) SendContext(ctx context.Context, message string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.Send(message)
+}
+
+func (a contextAdapter) SendScheduleSummaryContext(ctx context.Context, games []GameInfo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.SendScheduleSummary(games)
+}
+
+func (a contextAdapter) SendAlertContext(ctx context.Context, title, message string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.SendAlert(title, message)
+}
+
 // NoOpSender is a notification sender that does nothing.
 // It is used when notifications are disabled.
 type NoOpSender struct{}
@@ -41,6 +122,11 @@ func (n *NoOpSender) SendScheduleSummary(games []GameInfo) error {
 	return nil
 }
 
+// SendAlert does nothing and returns nil.
+func (n *NoOpSender) SendAlert(title, message string) error {
+	return nil
+}
+
 // IsEnabled always returns false for the no-op sender.
 func (n *NoOpSender) IsEnabled() bool {
 	return false
@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDiscordSender_SendContext_CancelledContext(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	s := WithContext(NewDiscordSender(server.URL))
+	if err := s.SendContext(ctx, "test"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("SendContext() error = %v, want the context's deadline error", err)
+	}
+}
+
+func TestWithContext_KeepsContextSenders(t *testing.T) {
+	discord := NewDiscordSender("https://discord.example.com/webhook")
+	if got := WithContext(discord); got != discord.(ContextSender) {
+		t.Errorf("WithContext(DiscordSender) = %T, want the sender itself", got)
+	}
+}
+
+func TestWithContext_AdaptsPlainSenders(t *testing.T) {
+	sender := &recordingSender{}
+	adapted := WithContext(sender)
+
+	ctx := context.Background()
+	if err := adapted.SendContext(ctx, "hello"); err != nil {
+		t.Fatalf("SendContext() returned error: %v", err)
+	}
+	if err := adapted.SendScheduleSummaryContext(ctx, []GameInfo{{ID: "1"}}); err != nil {
+		t.Fatalf("SendScheduleSummaryContext() returned error: %v", err)
+	}
+	if err := adapted.SendAlertContext(ctx, "Run aborted", "boom"); err != nil {
+		t.Fatalf("SendAlertContext() returned error: %v", err)
+	}
+	if len(sender.messages) != 1 || len(sender.summaries) != 1 || len(sender.alerts) != 1 {
+		t.Fatalf("sender recorded %d messages, %d summaries and %d alerts, want one of each",
+			len(sender.messages), len(sender.summaries), len(sender.alerts))
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := adapted.SendContext(cancelled, "late"); !errors.Is(err, context.Canceled) {
+		t.Errorf("SendContext() with a cancelled context error = %v, want context.Canceled", err)
+	}
+	if len(sender.messages) != 1 {
+		t.Errorf("sender recorded %d messages, want the cancelled send skipped", len(sender.messages))
+	}
+}
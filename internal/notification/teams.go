@@ -0,0 +1,64 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TeamLogoURLPattern is the NHL assets URL pattern for team logos, keyed by
+// uppercase NHL team abbreviation (e.g. "DAL").
+const TeamLogoURLPattern = "https://assets.nhle.com/logos/nhl/svg/%s_light.svg"
+
+// defaultTeamColor is used for teams without a known brand color.
+const defaultTeamColor = 3066993 // Green
+
+// teamColors maps NHL team IDs to their primary brand color.
+var teamColors = map[int]int{
+	24: 0xF47A38, // Anaheim Ducks
+	53: 0x8C2633, // Arizona Coyotes
+	1:  0xFFB81C, // Boston Bruins
+	7:  0x003087, // Buffalo Sabres
+	12: 0xCE1126, // Carolina Hurricanes
+	29: 0x002654, // Columbus Blue Jackets
+	20: 0xC8102E, // Calgary Flames
+	16: 0xCF0A2C, // Chicago Blackhawks
+	21: 0x6F263D, // Colorado Avalanche
+	25: 0x006847, // Dallas Stars
+	17: 0xCE1126, // Detroit Red Wings
+	22: 0xFF4C00, // Edmonton Oilers
+	13: 0xC8102E, // Florida Panthers
+	26: 0x111111, // Los Angeles Kings
+	30: 0x154734, // Minnesota Wild
+	8:  0xAF1E2D, // Montreal Canadiens
+	6:  0xCE1126, // New Jersey Devils
+	18: 0xFFB81C, // Nashville Predators
+	2:  0x00539B, // New York Islanders
+	3:  0x0038A8, // New York Rangers
+	9:  0xDA1A32, // Ottawa Senators
+	4:  0xF74902, // Philadelphia Flyers
+	5:  0xFCB514, // Pittsburgh Penguins
+	55: 0x99D9D9, // Seattle Kraken
+	28: 0x006D75, // San Jose Sharks
+	19: 0x002F87, // St. Louis Blues
+	14: 0x002868, // Tampa Bay Lightning
+	10: 0x00205B, // Toronto Maple Leafs
+	23: 0x00205B, // Vancouver Canucks
+	54: 0xB4975A, // Vegas Golden Knights
+	52: 0x041E42, // Winnipeg Jets
+	15: 0xC8102E, // Washington Capitals
+}
+
+// teamColor returns the brand color for a team ID, or the default color
+// if the team is unknown.
+func teamColor(teamID int) int {
+	if color, ok := teamColors[teamID]; ok {
+		return color
+	}
+	return defaultTeamColor
+}
+
+// teamLogoURL returns the logo URL for an NHL team abbreviation. The NHL
+// assets use the same abbreviations as the schedule API.
+func teamLogoURL(abbrev string) string {
+	return fmt.Sprintf(TeamLogoURLPattern, strings.ToUpper(abbrev))
+}
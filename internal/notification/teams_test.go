@@ -0,0 +1,34 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTeamLogoURL(t *testing.T) {
+	tests := []struct {
+		abbrev string
+		want   string
+	}{
+		{"DAL", "https://assets.nhle.com/logos/nhl/svg/DAL_light.svg"},
+		{"LAK", "https://assets.nhle.com/logos/nhl/svg/LAK_light.svg"},
+		{"NJD", "https://assets.nhle.com/logos/nhl/svg/NJD_light.svg"},
+		{"UTA", "https://assets.nhle.com/logos/nhl/svg/UTA_light.svg"},
+		{"tbl", "https://assets.nhle.com/logos/nhl/svg/TBL_light.svg"},
+	}
+
+	for _, tt := range tests {
+		if got := teamLogoURL(tt.abbrev); got != tt.want {
+			t.Errorf("teamLogoURL(%q) = %q, want %q", tt.abbrev, got, tt.want)
+		}
+	}
+}
+
+func TestTeamLogoURLUsesNHLAssets(t *testing.T) {
+	// Logos come from the NHL's own asset host, not a third-party CDN
+	for _, abbrev := range []string{"BOS", "CHI", "SJS", "XYZ"} {
+		if url := teamLogoURL(abbrev); !strings.HasPrefix(url, "https://assets.nhle.com/logos/nhl/") {
+			t.Errorf("teamLogoURL(%q) = %q, want an assets.nhle.com URL", abbrev, url)
+		}
+	}
+}
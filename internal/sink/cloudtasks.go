@@ -0,0 +1,208 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CloudTasks is a TaskSink that creates HTTP tasks on a Google Cloud Tasks
+// queue (or an emulator of it).
+type CloudTasks struct {
+	client    taskspb.CloudTasksClient
+	queuePath string
+}
+
+// NewCloudTasks creates a sink for the queue at
+// projects/PROJECT/locations/LOCATION/queues/QUEUE.
+func NewCloudTasks(client taskspb.CloudTasksClient, projectID, location, queue string) *CloudTasks {
+	return &CloudTasks{
+		client:    client,
+		queuePath: fmt.Sprintf("projects/%s/locations/%s/queues/%s", projectID, location, queue),
+	}
+}
+
+// QueuePath returns the full resource name of the sink's queue.
+func (c *CloudTasks) QueuePath() string {
+	return c.queuePath
+}
+
+// EnsureQueue creates the queue if it doesn't exist, or updates it, so that
+// the settings managed by config match. It reports whether the queue was
+// created and which settings it set or changed.
+func (c *CloudTasks) EnsureQueue(ctx context.Context, config QueueConfig) (bool, []QueueChange, error) {
+	queue, err := c.client.GetQueue(ctx, &taskspb.GetQueueRequest{Name: c.queuePath})
+	if status.Code(err) == codes.NotFound {
+		queue = &taskspb.Queue{Name: c.queuePath}
+		changes := config.diff(queue)
+		_, err = c.client.CreateQueue(ctx, &taskspb.CreateQueueRequest{
+			Parent: path.Dir(path.Dir(c.queuePath)),
+			Queue:  queue,
+		})
+		if err == nil {
+			return true, changes, nil
+		}
+		if status.Code(err) != codes.AlreadyExists {
+			return false, nil, fmt.Errorf("failed to create queue: %w", err)
+		}
+		// Created concurrently by another run; update it below
+		queue, err = c.client.GetQueue(ctx, &taskspb.GetQueueRequest{Name: c.queuePath})
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("failed to get queue: %w", err)
+	}
+
+	changes := config.diff(queue)
+	if len(changes) == 0 {
+		return false, nil, nil
+	}
+	mask := &fieldmaskpb.FieldMask{}
+	for _, change := range changes {
+		mask.Paths = append(mask.Paths, change.Field)
+	}
+	if _, err := c.client.UpdateQueue(ctx, &taskspb.UpdateQueueRequest{Queue: queue, UpdateMask: mask}); err != nil {
+		return false, nil, fmt.Errorf("failed to update queue: %w", err)
+	}
+	return false, changes, nil
+}
+
+// Schedule creates an HTTP task on the queue. A task name is relative to the
+// queue (e.g. "game-2024020712"); an empty name lets Cloud Tasks assign one.
+func (c *CloudTasks) Schedule(ctx context.Context, task Task) (Task, error) {
+	method, ok := taskspb.HttpMethod_value[task.method()]
+	if !ok {
+		return Task{}, fmt.Errorf("unsupported HTTP method %q", task.Method)
+	}
+
+	req := &taskspb.CreateTaskRequest{
+		Parent: c.queuePath,
+		Task: &taskspb.Task{
+			Name: c.taskPath(task.Name),
+			MessageType: &taskspb.Task_HttpRequest{
+				HttpRequest: &taskspb.HttpRequest{
+					HttpMethod: taskspb.HttpMethod(method),
+					Url:        task.URL,
+					Headers:    task.Headers,
+					Body:       task.Body,
+				},
+			},
+			ScheduleTime: timestamppb.New(task.ScheduleTime),
+		},
+	}
+
+	created, err := c.client.CreateTask(ctx, req)
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return Task{Name: c.taskPath(task.Name)}, fmt.Errorf("%w: %s: %v", ErrAlreadyExists, task.Name, err)
+		}
+		return Task{}, fmt.Errorf("failed to create task: %w", err)
+	}
+	return fromCloudTask(created), nil
+}
+
+// Cancel deletes the task from the queue.
+func (c *CloudTasks) Cancel(ctx context.Context, name string) error {
+	_, err := c.client.DeleteTask(ctx, &taskspb.DeleteTaskRequest{Name: c.taskPath(name)})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: %s: %v", ErrNotFound, name, err)
+		}
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+	return nil
+}
+
+// List returns every task on the queue, with bodies, ordered by schedule time.
+func (c *CloudTasks) List(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	req := &taskspb.ListTasksRequest{
+		Parent:       c.queuePath,
+		ResponseView: taskspb.Task_FULL,
+	}
+	for {
+		resp, err := c.client.ListTasks(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tasks: %w", err)
+		}
+		for _, task := range resp.Tasks {
+			tasks = append(tasks, fromCloudTask(task))
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		req.PageToken = resp.NextPageToken
+	}
+
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+// Run asks Cloud Tasks to dispatch a task now, even if the queue is paused.
+func (c *CloudTasks) Run(ctx context.Context, name string) error {
+	_, err := c.client.RunTask(ctx, &taskspb.RunTaskRequest{Name: c.taskPath(name)})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return fmt.Errorf("%w: %s: %v", ErrNotFound, name, err)
+		}
+		return fmt.Errorf("failed to run task: %w", err)
+	}
+	return nil
+}
+
+// Pause stops the queue from dispatching tasks; they stay queued.
+func (c *CloudTasks) Pause(ctx context.Context) (*taskspb.Queue, error) {
+	queue, err := c.client.PauseQueue(ctx, &taskspb.PauseQueueRequest{Name: c.queuePath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause queue: %w", err)
+	}
+	return queue, nil
+}
+
+// Resume restarts dispatching on a paused queue.
+func (c *CloudTasks) Resume(ctx context.Context) (*taskspb.Queue, error) {
+	queue, err := c.client.ResumeQueue(ctx, &taskspb.ResumeQueueRequest{Name: c.queuePath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume queue: %w", err)
+	}
+	return queue, nil
+}
+
+// Purge deletes every task on the queue.
+func (c *CloudTasks) Purge(ctx context.Context) (*taskspb.Queue, error) {
+	queue, err := c.client.PurgeQueue(ctx, &taskspb.PurgeQueueRequest{Name: c.queuePath})
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge queue: %w", err)
+	}
+	return queue, nil
+}
+
+// taskPath returns the full resource name for a task name relative to the
+// queue. Empty names and names that are already full paths pass through.
+func (c *CloudTasks) taskPath(name string) string {
+	if name == "" || strings.HasPrefix(name, c.queuePath+"/tasks/") {
+		return name
+	}
+	return c.queuePath + "/tasks/" + name
+}
+
+// fromCloudTask converts a Cloud Tasks task to a sink Task.
+func fromCloudTask(task *taskspb.Task) Task {
+	result := Task{
+		Name:         task.GetName(),
+		ScheduleTime: task.GetScheduleTime().AsTime(),
+	}
+	if req := task.GetHttpRequest(); req != nil {
+		result.URL = req.GetUrl()
+		result.Method = req.GetHttpMethod().String()
+		result.Headers = req.GetHeaders()
+		result.Body = req.GetBody()
+	}
+	return result
+}
@@ -0,0 +1,330 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// fakeCloudTasksServer keeps queues and tasks in memory, implementing the
+// RPCs the CloudTasks sink uses.
+type fakeCloudTasksServer struct {
+	taskspb.UnimplementedCloudTasksServer
+
+	mu      sync.Mutex
+	queues  map[string]*taskspb.Queue
+	tasks   map[string]*taskspb.Task
+	nextID  int
+	updates []*taskspb.UpdateQueueRequest
+}
+
+func newFakeCloudTasksServer() *fakeCloudTasksServer {
+	return &fakeCloudTasksServer{queues: make(map[string]*taskspb.Queue), tasks: make(map[string]*taskspb.Task)}
+}
+
+func (s *fakeCloudTasksServer) CreateQueue(ctx context.Context, req *taskspb.CreateQueueRequest) (*taskspb.Queue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.queues[req.Queue.Name]; ok {
+		return nil, status.Error(codes.AlreadyExists, "queue already exists")
+	}
+	s.queues[req.Queue.Name] = proto.Clone(req.Queue).(*taskspb.Queue)
+	return req.Queue, nil
+}
+
+func (s *fakeCloudTasksServer) GetQueue(ctx context.Context, req *taskspb.GetQueueRequest) (*taskspb.Queue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	queue, ok := s.queues[req.Name]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "queue not found")
+	}
+	return proto.Clone(queue).(*taskspb.Queue), nil
+}
+
+// UpdateQueue records the request and replaces the queue wholesale; the
+// tests check the field mask themselves.
+func (s *fakeCloudTasksServer) UpdateQueue(ctx context.Context, req *taskspb.UpdateQueueRequest) (*taskspb.Queue, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updates = append(s.updates, req)
+	s.queues[req.Queue.Name] = proto.Clone(req.Queue).(*taskspb.Queue)
+	return req.Queue, nil
+}
+
+func (s *fakeCloudTasksServer) CreateTask(ctx context.Context, req *taskspb.CreateTaskRequest) (*taskspb.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task := proto.Clone(req.Task).(*taskspb.Task)
+	if task.Name == "" {
+		s.nextID++
+		task.Name = fmt.Sprintf("%s/tasks/%d", req.Parent, s.nextID)
+	}
+	if _, ok := s.tasks[task.Name]; ok {
+		return nil, status.Error(codes.AlreadyExists, "task already exists")
+	}
+	s.tasks[task.Name] = task
+	return task, nil
+}
+
+func (s *fakeCloudTasksServer) DeleteTask(ctx context.Context, req *taskspb.DeleteTaskRequest) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tasks[req.Name]; !ok {
+		return nil, status.Error(codes.NotFound, "task not found")
+	}
+	delete(s.tasks, req.Name)
+	return &emptypb.Empty{}, nil
+}
+
+// ListTasks returns one task per page, so callers must follow page tokens.
+func (s *fakeCloudTasksServer) ListTasks(ctx context.Context, req *taskspb.ListTasksRequest) (*taskspb.ListTasksResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for name := range s.tasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	start := 0
+	if req.PageToken != "" {
+		start, _ = strconv.Atoi(req.PageToken)
+	}
+	if start >= len(names) {
+		return &taskspb.ListTasksResponse{}, nil
+	}
+	resp := &taskspb.ListTasksResponse{Tasks: []*taskspb.Task{s.tasks[names[start]]}}
+	if start+1 < len(names) {
+		resp.NextPageToken = strconv.Itoa(start + 1)
+	}
+	return resp, nil
+}
+
+// newTestCloudTasks starts the fake server on an in-memory listener and
+// returns a sink connected to it.
+func newTestCloudTasks(t *testing.T) (*CloudTasks, *fakeCloudTasksServer) {
+	t.Helper()
+
+	server := newFakeCloudTasksServer()
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	taskspb.RegisterCloudTasksServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial fake server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewCloudTasks(taskspb.NewCloudTasksClient(conn), "proj", "us-south1", "gameschedule"), server
+}
+
+func TestCloudTasksEnsureQueue(t *testing.T) {
+	c, server := newTestCloudTasks(t)
+	ctx := context.Background()
+
+	created, changes, err := c.EnsureQueue(ctx, QueueConfig{})
+	if err != nil || !created || len(changes) != 0 {
+		t.Fatalf("first EnsureQueue() = %t, %v, %v, want true, no changes, nil", created, changes, err)
+	}
+	created, changes, err = c.EnsureQueue(ctx, QueueConfig{})
+	if err != nil || created || len(changes) != 0 {
+		t.Errorf("second EnsureQueue() = %t, %v, %v, want false, no changes, nil", created, changes, err)
+	}
+	if len(server.updates) != 0 {
+		t.Errorf("EnsureQueue() sent %d updates for an unmanaged queue, want none", len(server.updates))
+	}
+}
+
+func TestCloudTasksEnsureQueueCreatesWithConfig(t *testing.T) {
+	c, server := newTestCloudTasks(t)
+
+	created, changes, err := c.EnsureQueue(context.Background(), QueueConfig{
+		MaxDispatchesPerSecond: 5,
+		MaxAttempts:            3,
+		MinBackoff:             10 * time.Second,
+	})
+	if err != nil || !created {
+		t.Fatalf("EnsureQueue() = %t, %v, want true, nil", created, err)
+	}
+	if len(changes) != 3 {
+		t.Errorf("EnsureQueue() changes = %v, want the 3 configured settings", changes)
+	}
+
+	queue := server.queues[c.QueuePath()]
+	if queue.GetRateLimits().GetMaxDispatchesPerSecond() != 5 ||
+		queue.GetRetryConfig().GetMaxAttempts() != 3 ||
+		queue.GetRetryConfig().GetMinBackoff().AsDuration() != 10*time.Second {
+		t.Errorf("created queue = %v, want the configured settings", queue)
+	}
+	if queue.GetRateLimits().GetMaxConcurrentDispatches() != 0 || queue.GetRetryConfig().GetMaxBackoff() != nil {
+		t.Errorf("created queue = %v, want unmanaged settings left unset", queue)
+	}
+}
+
+func TestCloudTasksEnsureQueueUpdatesChangedSettings(t *testing.T) {
+	c, server := newTestCloudTasks(t)
+	ctx := context.Background()
+
+	server.queues[c.QueuePath()] = &taskspb.Queue{
+		Name:        c.QueuePath(),
+		RateLimits:  &taskspb.RateLimits{MaxDispatchesPerSecond: 500, MaxConcurrentDispatches: 1000},
+		RetryConfig: &taskspb.RetryConfig{MaxAttempts: 100, MaxBackoff: durationpb.New(time.Hour)},
+	}
+
+	config := QueueConfig{
+		MaxDispatchesPerSecond:  500, // unchanged
+		MaxConcurrentDispatches: 10,
+		MaxBackoff:              5 * time.Minute,
+		LogSamplingRatio:        0.5,
+	}
+	created, changes, err := c.EnsureQueue(ctx, config)
+	if err != nil || created {
+		t.Fatalf("EnsureQueue() = %t, %v, want false, nil", created, err)
+	}
+
+	var diff []string
+	for _, change := range changes {
+		diff = append(diff, change.String())
+	}
+	want := []string{
+		"rate_limits.max_concurrent_dispatches: 1000 -> 10",
+		"retry_config.max_backoff: 1h0m0s -> 5m0s",
+		"stackdriver_logging_config.sampling_ratio: default -> 0.5",
+	}
+	if strings.Join(diff, "\n") != strings.Join(want, "\n") {
+		t.Errorf("EnsureQueue() diff =\n%s\nwant\n%s", strings.Join(diff, "\n"), strings.Join(want, "\n"))
+	}
+
+	if len(server.updates) != 1 {
+		t.Fatalf("EnsureQueue() sent %d updates, want 1", len(server.updates))
+	}
+	if got := server.updates[0].UpdateMask.GetPaths(); strings.Join(got, ",") != "rate_limits.max_concurrent_dispatches,retry_config.max_backoff,stackdriver_logging_config.sampling_ratio" {
+		t.Errorf("update mask = %v, want only the changed fields", got)
+	}
+	queue := server.queues[c.QueuePath()]
+	if queue.GetRetryConfig().GetMaxAttempts() != 100 || queue.GetRateLimits().GetMaxDispatchesPerSecond() != 500 {
+		t.Errorf("updated queue = %v, want unmanaged and unchanged settings kept", queue)
+	}
+
+	// The queue now matches, so a second run changes nothing
+	if _, changes, err := c.EnsureQueue(ctx, config); err != nil || len(changes) != 0 {
+		t.Errorf("second EnsureQueue() = %v, %v, want no changes", changes, err)
+	}
+	if len(server.updates) != 1 {
+		t.Errorf("second EnsureQueue() sent an update for a matching queue")
+	}
+}
+
+func TestCloudTasksScheduleAndList(t *testing.T) {
+	c, server := newTestCloudTasks(t)
+	ctx := context.Background()
+	at := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+
+	scheduled, err := c.Schedule(ctx, Task{
+		URL:          "http://host.docker.internal:8080",
+		Headers:      map[string]string{"Content-Type": "application/json"},
+		Body:         []byte(`{"game":{}}`),
+		ScheduleTime: at,
+	})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if scheduled.Name != "projects/proj/locations/us-south1/queues/gameschedule/tasks/1" {
+		t.Errorf("Name = %q, want server-assigned task path", scheduled.Name)
+	}
+
+	stored := server.tasks[scheduled.Name].GetHttpRequest()
+	if stored.GetHttpMethod() != taskspb.HttpMethod_POST {
+		t.Errorf("HttpMethod = %s, want POST", stored.GetHttpMethod())
+	}
+	if string(stored.GetBody()) != `{"game":{}}` {
+		t.Errorf("Body = %s, want the task body", stored.GetBody())
+	}
+
+	if _, err := c.Schedule(ctx, Task{Name: "game-2", URL: "http://example.com", ScheduleTime: at.Add(-time.Hour)}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	tasks, err := c.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("List() returned %d tasks, want 2", len(tasks))
+	}
+	if tasks[0].Name != c.QueuePath()+"/tasks/game-2" || !tasks[0].ScheduleTime.Equal(at.Add(-time.Hour)) {
+		t.Errorf("List()[0] = %s at %s, want game-2 first", tasks[0].Name, tasks[0].ScheduleTime)
+	}
+	if tasks[1].Method != "POST" || tasks[1].Headers["Content-Type"] != "application/json" {
+		t.Errorf("List()[1] = %+v, want POST with headers", tasks[1])
+	}
+}
+
+func TestCloudTasksScheduleDuplicateName(t *testing.T) {
+	c, _ := newTestCloudTasks(t)
+	ctx := context.Background()
+
+	if _, err := c.Schedule(ctx, Task{Name: "game-1"}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	existing, err := c.Schedule(ctx, Task{Name: "game-1"})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Schedule() error = %v, want ErrAlreadyExists", err)
+	}
+	if existing.Name != c.QueuePath()+"/tasks/game-1" {
+		t.Errorf("Schedule() name = %q, want the existing task's full name", existing.Name)
+	}
+}
+
+func TestCloudTasksCancel(t *testing.T) {
+	c, server := newTestCloudTasks(t)
+	ctx := context.Background()
+
+	if _, err := c.Schedule(ctx, Task{Name: "game-1"}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if err := c.Cancel(ctx, "game-1"); err != nil {
+		t.Fatalf("Cancel() returned error: %v", err)
+	}
+	if len(server.tasks) != 0 {
+		t.Errorf("server has %d tasks after Cancel(), want 0", len(server.tasks))
+	}
+	if err := c.Cancel(ctx, "game-1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Cancel() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestCloudTasksRejectsUnknownMethod(t *testing.T) {
+	c, _ := newTestCloudTasks(t)
+
+	if _, err := c.Schedule(context.Background(), Task{Method: "BREW"}); err == nil {
+		t.Error("Schedule() with method BREW succeeded, want error")
+	}
+}
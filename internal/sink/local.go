@@ -0,0 +1,320 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// LocalOptions configures a Local sink.
+type LocalOptions struct {
+	StatePath   string        // File pending tasks are persisted to; empty keeps them in memory only
+	HTTPClient  *http.Client  // Client that delivers tasks; nil uses one with a 30 second timeout
+	MaxAttempts int           // Delivery attempts per task; 0 uses 5
+	Backoff     time.Duration // Wait before the first retry, doubling per attempt up to a minute; 0 uses 1 second
+	Tick        time.Duration // Timer wheel resolution; 0 uses 1 second
+}
+
+// Local is a TaskSink that delivers tasks itself, replacing a Cloud Tasks
+// emulator for local development. Pending tasks are held in a timer wheel,
+// and when a task comes due its request (method, headers and body) is sent,
+// with retries. Pending tasks are persisted to disk, so a restart resumes
+// them; tasks that came due while the process was down are delivered at once.
+type Local struct {
+	options LocalOptions
+
+	mu     sync.Mutex
+	tasks  map[string]Task
+	wheel  *timerWheel
+	nextID int
+}
+
+// NewLocal creates a local sink, loading any tasks persisted at
+// options.StatePath. Call Run to start delivering them.
+func NewLocal(options LocalOptions) (*Local, error) {
+	if options.HTTPClient == nil {
+		options.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = 5
+	}
+	if options.Backoff <= 0 {
+		options.Backoff = time.Second
+	}
+	if options.Tick <= 0 {
+		options.Tick = time.Second
+	}
+
+	l := &Local{
+		options: options,
+		tasks:   make(map[string]Task),
+		wheel:   newTimerWheel(options.Tick, 3600, time.Now()),
+	}
+	if err := l.load(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Schedule adds the task to the wheel and persists it.
+func (l *Local) Schedule(ctx context.Context, task Task) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if task.Name == "" {
+		l.nextID++
+		task.Name = "tasks/" + strconv.Itoa(l.nextID)
+	}
+	if _, ok := l.tasks[task.Name]; ok {
+		return Task{Name: task.Name}, fmt.Errorf("%w: %s", ErrAlreadyExists, task.Name)
+	}
+
+	task.Method = task.method()
+	l.tasks[task.Name] = task
+	l.wheel.add(task.Name, task.ScheduleTime)
+	if err := l.save(); err != nil {
+		delete(l.tasks, task.Name)
+		l.wheel.remove(task.Name)
+		return Task{}, err
+	}
+	return task, nil
+}
+
+// Cancel removes a pending task. A delivery already in progress completes.
+func (l *Local) Cancel(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.tasks[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(l.tasks, name)
+	l.wheel.remove(name)
+	return l.save()
+}
+
+// List returns the pending tasks, including those being delivered, ordered
+// by schedule time.
+func (l *Local) List(ctx context.Context) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tasks := make([]Task, 0, len(l.tasks))
+	for _, task := range l.tasks {
+		tasks = append(tasks, task)
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+// Run delivers tasks as they come due until ctx is cancelled, then waits for
+// deliveries in progress to stop. Tasks interrupted mid-delivery stay
+// persisted and are retried on the next start.
+func (l *Local) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.options.Tick)
+	defer ticker.Stop()
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		for _, task := range l.due(time.Now()) {
+			wg.Add(1)
+			go func(task Task) {
+				defer wg.Done()
+				l.deliver(ctx, task)
+			}(task)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// due advances the wheel to now and returns the tasks that came due.
+func (l *Local) due(now time.Time) []Task {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var tasks []Task
+	for _, name := range l.wheel.advance(now) {
+		if task, ok := l.tasks[name]; ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+// deliver sends the task's request, retrying with exponential backoff, and
+// removes the task once it succeeds or runs out of attempts.
+func (l *Local) deliver(ctx context.Context, task Task) {
+	backoff := l.options.Backoff
+	for attempt := 1; ; attempt++ {
+		err := l.send(ctx, task)
+		if err == nil {
+			slog.Info("Delivered task", "task", task.Name, "url", task.URL, "attempt", attempt)
+			l.finish(task.Name)
+			return
+		}
+		if ctx.Err() != nil {
+			// Stopping: leave the task persisted so the next start retries it
+			l.release(task.Name)
+			return
+		}
+		if attempt >= l.options.MaxAttempts {
+			slog.Error("Giving up on task", "task", task.Name, "url", task.URL, "attempts", attempt, "error", err)
+			l.finish(task.Name)
+			return
+		}
+
+		slog.Warn("Task delivery failed, retrying", "task", task.Name, "attempt", attempt, "retry_in", backoff, "error", err)
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			l.release(task.Name)
+			return
+		case <-timer.C:
+		}
+		backoff = min(2*backoff, time.Minute)
+	}
+}
+
+// send makes one delivery attempt; any non-2xx response is an error.
+func (l *Local) send(ctx context.Context, task Task) error {
+	req, err := http.NewRequestWithContext(ctx, task.method(), task.URL, bytes.NewReader(task.Body))
+	if err != nil {
+		return err
+	}
+	for key, value := range task.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := l.options.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("target returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// finish forgets a delivered (or abandoned) task.
+func (l *Local) finish(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.tasks, name)
+	if err := l.save(); err != nil {
+		slog.Warn("Failed to persist local tasks", "error", err)
+	}
+}
+
+// release returns an interrupted task to the wheel.
+func (l *Local) release(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if task, ok := l.tasks[name]; ok {
+		l.wheel.add(name, task.ScheduleTime)
+	}
+}
+
+// localState is the on-disk form of the pending tasks.
+type localState struct {
+	NextID int    `json:"nextId"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// load reads the persisted tasks, if any, into the wheel.
+func (l *Local) load() error {
+	if l.options.StatePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(l.options.StatePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read local task state: %w", err)
+	}
+
+	var state localState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("failed to parse local task state %s: %w", l.options.StatePath, err)
+	}
+
+	l.nextID = state.NextID
+	for _, task := range state.Tasks {
+		l.tasks[task.Name] = task
+		l.wheel.add(task.Name, task.ScheduleTime)
+	}
+	return nil
+}
+
+// save atomically writes the pending tasks to the state file. The caller
+// must hold l.mu.
+func (l *Local) save() error {
+	if l.options.StatePath == "" {
+		return nil
+	}
+
+	state := localState{NextID: l.nextID, Tasks: make([]Task, 0, len(l.tasks))}
+	for _, task := range l.tasks {
+		state.Tasks = append(state.Tasks, task)
+	}
+	sortTasks(state.Tasks)
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode local task state: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.options.StatePath), filepath.Base(l.options.StatePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write local task state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write local task state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write local task state: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), l.options.StatePath); err != nil {
+		return fmt.Errorf("failed to write local task state: %w", err)
+	}
+	return nil
+}
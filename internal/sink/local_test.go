@@ -0,0 +1,247 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordingServer records the requests it receives and fails the first
+// failures of them with a 503.
+type recordingServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	requests []recordedRequest
+	received chan struct{}
+}
+
+type recordedRequest struct {
+	method string
+	header http.Header
+	body   string
+}
+
+func newRecordingServer(t *testing.T, failures int) *recordingServer {
+	t.Helper()
+
+	s := &recordingServer{failures: failures, received: make(chan struct{}, 100)}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.requests = append(s.requests, recordedRequest{method: r.Method, header: r.Header.Clone(), body: string(body)})
+		fail := s.failures > 0
+		if fail {
+			s.failures--
+		}
+		s.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		s.received <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// waitForRequests waits until the server has received n requests.
+func (s *recordingServer) waitForRequests(t *testing.T, n int) []recordedRequest {
+	t.Helper()
+
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.received:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for request %d of %d", i+1, n)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]recordedRequest(nil), s.requests...)
+}
+
+// runLocal starts delivering tasks and stops when the test ends.
+func runLocal(t *testing.T, l *Local) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		l.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+// waitForEmpty waits until the sink has no pending tasks.
+func waitForEmpty(t *testing.T, l *Local) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if tasks, _ := l.List(context.Background()); len(tasks) == 0 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for pending tasks to drain")
+}
+
+func newTestLocal(t *testing.T, statePath string) *Local {
+	t.Helper()
+
+	l, err := NewLocal(LocalOptions{StatePath: statePath, Tick: 10 * time.Millisecond, Backoff: time.Millisecond, MaxAttempts: 3})
+	if err != nil {
+		t.Fatalf("NewLocal() returned error: %v", err)
+	}
+	return l
+}
+
+func TestLocalDeliversRequestAtScheduleTime(t *testing.T) {
+	server := newRecordingServer(t, 0)
+	l := newTestLocal(t, "")
+	runLocal(t, l)
+
+	scheduledAt := time.Now().Add(50 * time.Millisecond)
+	_, err := l.Schedule(context.Background(), Task{
+		URL:          server.URL,
+		Method:       http.MethodPut,
+		Headers:      map[string]string{"Content-Type": "application/json", "X-Run-Id": "run-1"},
+		Body:         []byte(`{"game":{"id":"2024020712"}}`),
+		ScheduleTime: scheduledAt,
+	})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	requests := server.waitForRequests(t, 1)
+	if time.Now().Before(scheduledAt) {
+		t.Error("task delivered before its schedule time")
+	}
+
+	req := requests[0]
+	if req.method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", req.method)
+	}
+	if req.header.Get("X-Run-Id") != "run-1" || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("headers = %v, want task headers", req.header)
+	}
+	if req.body != `{"game":{"id":"2024020712"}}` {
+		t.Errorf("body = %s, want task body", req.body)
+	}
+	waitForEmpty(t, l)
+}
+
+func TestLocalRetriesFailedDeliveries(t *testing.T) {
+	server := newRecordingServer(t, 2)
+	l := newTestLocal(t, "")
+	runLocal(t, l)
+
+	if _, err := l.Schedule(context.Background(), Task{URL: server.URL, ScheduleTime: time.Now()}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	server.waitForRequests(t, 3)
+	waitForEmpty(t, l)
+}
+
+func TestLocalGivesUpAfterMaxAttempts(t *testing.T) {
+	server := newRecordingServer(t, 10)
+	l := newTestLocal(t, "")
+	runLocal(t, l)
+
+	if _, err := l.Schedule(context.Background(), Task{URL: server.URL, ScheduleTime: time.Now()}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	server.waitForRequests(t, 3)
+	waitForEmpty(t, l)
+
+	time.Sleep(50 * time.Millisecond)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if len(server.requests) != 3 {
+		t.Errorf("server received %d requests, want 3", len(server.requests))
+	}
+}
+
+func TestLocalPersistsPendingTasks(t *testing.T) {
+	server := newRecordingServer(t, 0)
+	statePath := filepath.Join(t.TempDir(), "tasks.json")
+
+	first := newTestLocal(t, statePath)
+	scheduled, err := first.Schedule(context.Background(), Task{URL: server.URL, Body: []byte("payload"), ScheduleTime: time.Now().Add(100 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if _, err := first.Schedule(context.Background(), Task{URL: server.URL, ScheduleTime: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	// A restarted sink picks the tasks up from disk and keeps numbering names
+	restarted := newTestLocal(t, statePath)
+	tasks, _ := restarted.List(context.Background())
+	if len(tasks) != 2 || tasks[0].Name != scheduled.Name || string(tasks[0].Body) != "payload" {
+		t.Fatalf("restarted List() = %+v, want the persisted tasks", tasks)
+	}
+	next, err := restarted.Schedule(context.Background(), Task{URL: server.URL, ScheduleTime: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if next.Name == tasks[0].Name || next.Name == tasks[1].Name {
+		t.Errorf("new task reused name %q", next.Name)
+	}
+
+	runLocal(t, restarted)
+	requests := server.waitForRequests(t, 1)
+	if requests[0].body != "payload" {
+		t.Errorf("delivered body = %q, want %q", requests[0].body, "payload")
+	}
+
+	// Delivered tasks are removed from disk
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		reloaded := newTestLocal(t, statePath)
+		if tasks, _ := reloaded.List(context.Background()); len(tasks) == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("delivered task was not removed from the state file")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLocalCancel(t *testing.T) {
+	l := newTestLocal(t, filepath.Join(t.TempDir(), "tasks.json"))
+	ctx := context.Background()
+
+	task, err := l.Schedule(ctx, Task{Name: "game-1", ScheduleTime: time.Now().Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if _, err := l.Schedule(ctx, Task{Name: "game-1"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("duplicate Schedule() error = %v, want ErrAlreadyExists", err)
+	}
+	if err := l.Cancel(ctx, task.Name); err != nil {
+		t.Fatalf("Cancel() returned error: %v", err)
+	}
+	if err := l.Cancel(ctx, task.Name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Cancel() error = %v, want ErrNotFound", err)
+	}
+	if l.wheel.len() != 0 {
+		t.Errorf("wheel holds %d tasks after Cancel(), want 0", l.wheel.len())
+	}
+}
@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Memory is a TaskSink that keeps tasks in memory and never delivers them.
+// It backs dry runs and tests.
+type Memory struct {
+	mu     sync.Mutex
+	tasks  map[string]Task
+	nextID int
+}
+
+// NewMemory creates an empty in-memory sink.
+func NewMemory() *Memory {
+	return &Memory{tasks: make(map[string]Task)}
+}
+
+// Schedule stores the task, assigning a name if it has none.
+func (m *Memory) Schedule(ctx context.Context, task Task) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if task.Name == "" {
+		m.nextID++
+		task.Name = fmt.Sprintf("tasks/%d", m.nextID)
+	}
+	if _, ok := m.tasks[task.Name]; ok {
+		return Task{Name: task.Name}, fmt.Errorf("%w: %s", ErrAlreadyExists, task.Name)
+	}
+
+	task.Method = task.method()
+	m.tasks[task.Name] = task
+	return task, nil
+}
+
+// Cancel removes the named task.
+func (m *Memory) Cancel(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tasks[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(m.tasks, name)
+	return nil
+}
+
+// List returns the stored tasks ordered by schedule time.
+func (m *Memory) List(ctx context.Context) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks := make([]Task, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		tasks = append(tasks, task)
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
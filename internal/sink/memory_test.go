@@ -0,0 +1,105 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryScheduleAssignsNames(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	first, err := m.Schedule(ctx, Task{URL: "http://example.com"})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	second, err := m.Schedule(ctx, Task{URL: "http://example.com"})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	if first.Name == "" || first.Name == second.Name {
+		t.Errorf("names = %q, %q, want distinct non-empty names", first.Name, second.Name)
+	}
+	if first.Method != http.MethodPost {
+		t.Errorf("Method = %q, want POST by default", first.Method)
+	}
+}
+
+func TestMemoryScheduleDuplicateName(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	if _, err := m.Schedule(ctx, Task{Name: "game-1"}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	_, err := m.Schedule(ctx, Task{Name: "game-1"})
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("Schedule() error = %v, want ErrAlreadyExists", err)
+	}
+}
+
+func TestMemoryListOrdersBySchedule(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	base := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+
+	for _, task := range []Task{
+		{Name: "late", ScheduleTime: base.Add(2 * time.Hour)},
+		{Name: "early", ScheduleTime: base},
+		{Name: "middle", ScheduleTime: base.Add(time.Hour)},
+	} {
+		if _, err := m.Schedule(ctx, task); err != nil {
+			t.Fatalf("Schedule() returned error: %v", err)
+		}
+	}
+
+	tasks, err := m.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	want := []string{"early", "middle", "late"}
+	if len(names) != len(want) {
+		t.Fatalf("List() = %v, want %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("List()[%d] = %q, want %q", i, names[i], want[i])
+		}
+	}
+}
+
+func TestMemoryCancel(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+
+	task, err := m.Schedule(ctx, Task{})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if err := m.Cancel(ctx, task.Name); err != nil {
+		t.Fatalf("Cancel() returned error: %v", err)
+	}
+	if tasks, _ := m.List(ctx); len(tasks) != 0 {
+		t.Errorf("List() after Cancel() = %d tasks, want 0", len(tasks))
+	}
+	if err := m.Cancel(ctx, task.Name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Cancel() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestMemoryHonorsCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewMemory().Schedule(ctx, Task{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Schedule() error = %v, want context.Canceled", err)
+	}
+}
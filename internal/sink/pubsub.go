@@ -0,0 +1,144 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Pub/Sub message attributes set on every published task, in addition to the
+// task's own Attributes.
+const (
+	// AttrScheduleTime is when the task was scheduled to run (RFC 3339).
+	AttrScheduleTime = "scheduleTime"
+	// AttrDeliverAt is when the subscriber should act on the message
+	// (RFC 3339). Pub/Sub delivers at once, so subscribers must hold the
+	// message until then.
+	AttrDeliverAt = "deliverAt"
+	// AttrTaskName carries the task's name, if it had one, so subscribers can
+	// drop duplicates.
+	AttrTaskName = "taskName"
+)
+
+// PubSub is a TaskSink that publishes each task's body to a Pub/Sub topic
+// for a Pub/Sub-triggered consumer. Messages are published immediately with
+// a deliverAt attribute; the task's URL, method and headers are not used.
+// Published messages can't be withdrawn, so Cancel is unsupported and List
+// only returns the tasks published by this sink.
+type PubSub struct {
+	client *pubsub.Client
+	topic  *pubsub.Topic
+
+	mu        sync.Mutex
+	published map[string]Task
+}
+
+// NewPubSub creates a sink that publishes to the topic with the given ID.
+// The client honors PUBSUB_EMULATOR_HOST, so the same sink works against
+// the Pub/Sub emulator.
+func NewPubSub(client *pubsub.Client, topicID string) *PubSub {
+	return &PubSub{client: client, topic: client.Topic(topicID), published: make(map[string]Task)}
+}
+
+// TopicPath returns the full resource name of the topic.
+func (p *PubSub) TopicPath() string {
+	return p.topic.String()
+}
+
+// EnsureTopic creates the topic if it doesn't exist yet. It reports whether
+// the topic was created.
+func (p *PubSub) EnsureTopic(ctx context.Context) (bool, error) {
+	exists, err := p.topic.Exists(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to check topic %s: %w", p.TopicPath(), err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	_, err = p.client.CreateTopic(ctx, p.topic.ID())
+	if status.Code(err) == codes.AlreadyExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to create topic %s: %w", p.TopicPath(), err)
+	}
+	return true, nil
+}
+
+// Schedule publishes the task's body with its attributes plus scheduleTime
+// and deliverAt, and returns it named after the message ID. A named task is
+// published at most once per sink.
+func (p *PubSub) Schedule(ctx context.Context, task Task) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+
+	p.mu.Lock()
+	_, duplicate := p.published[task.Name]
+	p.mu.Unlock()
+	if task.Name != "" && duplicate {
+		return Task{Name: task.Name}, fmt.Errorf("%w: %s", ErrAlreadyExists, task.Name)
+	}
+
+	attributes := make(map[string]string, len(task.Attributes)+3)
+	for key, value := range task.Attributes {
+		attributes[key] = value
+	}
+	at := task.ScheduleTime.UTC().Format(time.RFC3339)
+	attributes[AttrScheduleTime] = at
+	attributes[AttrDeliverAt] = at
+	if task.Name != "" {
+		attributes[AttrTaskName] = task.Name
+	}
+
+	id, err := p.topic.Publish(ctx, &pubsub.Message{Data: task.Body, Attributes: attributes}).Get(ctx)
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to publish to %s: %w", p.TopicPath(), err)
+	}
+
+	if task.Name == "" {
+		task.Name = p.TopicPath() + "/messages/" + id
+	}
+	task.Method = task.method()
+	task.Attributes = attributes
+
+	p.mu.Lock()
+	p.published[task.Name] = task
+	p.mu.Unlock()
+	return task, nil
+}
+
+// Cancel always fails: a published message can't be withdrawn.
+func (p *PubSub) Cancel(ctx context.Context, name string) error {
+	return fmt.Errorf("cannot cancel %s: published Pub/Sub messages can't be withdrawn: %w", name, errors.ErrUnsupported)
+}
+
+// List returns the tasks published by this sink, ordered by schedule time.
+// Pub/Sub offers no way to list the messages already in a topic.
+func (p *PubSub) List(ctx context.Context) ([]Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	tasks := make([]Task, 0, len(p.published))
+	for _, task := range p.published {
+		tasks = append(tasks, task)
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+// Stop flushes messages still being published.
+func (p *PubSub) Stop() {
+	p.topic.Stop()
+}
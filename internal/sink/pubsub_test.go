@@ -0,0 +1,152 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub"
+	"cloud.google.com/go/pubsub/pstest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newTestPubSub starts a fake Pub/Sub server and returns a sink connected to
+// it, with the fake to inspect published messages.
+func newTestPubSub(t *testing.T) (*PubSub, *pstest.Server) {
+	t.Helper()
+
+	server := pstest.NewServer()
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := grpc.Dial(server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("failed to dial fake server: %v", err)
+	}
+	client, err := pubsub.NewClient(context.Background(), "proj", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("pubsub.NewClient() returned error: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	p := NewPubSub(client, "gameschedule")
+	t.Cleanup(p.Stop)
+	return p, server
+}
+
+func TestPubSubEnsureTopic(t *testing.T) {
+	p, _ := newTestPubSub(t)
+	ctx := context.Background()
+
+	created, err := p.EnsureTopic(ctx)
+	if err != nil || !created {
+		t.Fatalf("first EnsureTopic() = %t, %v, want true, nil", created, err)
+	}
+	created, err = p.EnsureTopic(ctx)
+	if err != nil || created {
+		t.Errorf("second EnsureTopic() = %t, %v, want false, nil", created, err)
+	}
+	if p.TopicPath() != "projects/proj/topics/gameschedule" {
+		t.Errorf("TopicPath() = %q, want the full topic name", p.TopicPath())
+	}
+}
+
+func TestPubSubPublishesBodyWithAttributes(t *testing.T) {
+	p, server := newTestPubSub(t)
+	ctx := context.Background()
+	if _, err := p.EnsureTopic(ctx); err != nil {
+		t.Fatalf("EnsureTopic() returned error: %v", err)
+	}
+
+	at := time.Date(2025, 1, 15, 18, 55, 0, 0, time.FixedZone("CST", -6*3600))
+	scheduled, err := p.Schedule(ctx, Task{
+		URL:          "http://ignored.example.com",
+		Body:         []byte(`{"game":{"id":"2024030411"}}`),
+		ScheduleTime: at,
+		Attributes:   map[string]string{"gameId": "2024030411", "executionEnd": "2025-01-16T04:55:00Z"},
+	})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(messages))
+	}
+	message := messages[0]
+	if scheduled.Name != p.TopicPath()+"/messages/"+message.ID {
+		t.Errorf("Name = %q, want it named after message %s", scheduled.Name, message.ID)
+	}
+	if string(message.Data) != `{"game":{"id":"2024030411"}}` {
+		t.Errorf("Data = %s, want the task body", message.Data)
+	}
+
+	want := map[string]string{
+		"gameId":         "2024030411",
+		"executionEnd":   "2025-01-16T04:55:00Z",
+		AttrScheduleTime: "2025-01-16T00:55:00Z",
+		AttrDeliverAt:    "2025-01-16T00:55:00Z",
+	}
+	for key, value := range want {
+		if message.Attributes[key] != value {
+			t.Errorf("attribute %s = %q, want %q", key, message.Attributes[key], value)
+		}
+	}
+	if _, ok := message.Attributes[AttrTaskName]; ok {
+		t.Errorf("unnamed task published with a %s attribute", AttrTaskName)
+	}
+}
+
+func TestPubSubNamedTasksPublishOnce(t *testing.T) {
+	p, server := newTestPubSub(t)
+	ctx := context.Background()
+	if _, err := p.EnsureTopic(ctx); err != nil {
+		t.Fatalf("EnsureTopic() returned error: %v", err)
+	}
+
+	if _, err := p.Schedule(ctx, Task{Name: "game-1"}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if _, err := p.Schedule(ctx, Task{Name: "game-1"}); !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("second Schedule() error = %v, want ErrAlreadyExists", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 || messages[0].Attributes[AttrTaskName] != "game-1" {
+		t.Errorf("server received %v, want one message carrying the task name", messages)
+	}
+}
+
+func TestPubSubListAndCancel(t *testing.T) {
+	p, _ := newTestPubSub(t)
+	ctx := context.Background()
+	if _, err := p.EnsureTopic(ctx); err != nil {
+		t.Fatalf("EnsureTopic() returned error: %v", err)
+	}
+
+	at := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+	late, _ := p.Schedule(ctx, Task{ScheduleTime: at})
+	early, _ := p.Schedule(ctx, Task{ScheduleTime: at.Add(-time.Hour)})
+
+	tasks, err := p.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	if len(tasks) != 2 || tasks[0].Name != early.Name || tasks[1].Name != late.Name {
+		t.Errorf("List() = %v, want the published tasks by schedule time", tasks)
+	}
+
+	if err := p.Cancel(ctx, early.Name); !errors.Is(err, errors.ErrUnsupported) {
+		t.Errorf("Cancel() error = %v, want ErrUnsupported", err)
+	}
+}
+
+func TestPubSubScheduleFailsWithoutTopic(t *testing.T) {
+	p, _ := newTestPubSub(t)
+
+	if _, err := p.Schedule(context.Background(), Task{}); err == nil {
+		t.Error("Schedule() to a missing topic returned nil error")
+	}
+}
@@ -0,0 +1,180 @@
+package sink
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	taskspb "google.golang.org/genproto/googleapis/cloud/tasks/v2"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// QueueConfig declares the Cloud Tasks queue settings EnsureQueue manages.
+// A zero field is not managed: the queue keeps whatever value it has, which
+// for a new queue is the Cloud Tasks default.
+type QueueConfig struct {
+	MaxDispatchesPerSecond  float64       // Rate limit for dispatching tasks
+	MaxConcurrentDispatches int32         // Limit on tasks dispatched but not yet answered
+	MaxAttempts             int32         // Attempts per task, including the first; -1 is unlimited
+	MinBackoff              time.Duration // Wait before the first retry
+	MaxBackoff              time.Duration // Cap on the wait between retries
+	MaxRetryDuration        time.Duration // How long after the first attempt a task may still be retried
+	LogSamplingRatio        float64       // Fraction of task operations logged to Cloud Logging, 0 to 1
+}
+
+// QueueChange is one queue setting that EnsureQueue changed.
+type QueueChange struct {
+	Field string // Field path, as used in the UpdateQueue field mask
+	From  string // Previous value; "default" if it was unset
+	To    string // New value
+}
+
+// String formats the change as one line of a diff.
+func (c QueueChange) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Field, c.From, c.To)
+}
+
+// queueField is one managed queue setting.
+type queueField struct {
+	path string
+	// want returns the configured value and whether the field is managed.
+	want func(QueueConfig) (string, bool)
+	// get returns the queue's current value, or "" if it is unset.
+	get func(*taskspb.Queue) string
+	// set copies the configured value into the queue.
+	set func(*taskspb.Queue, QueueConfig)
+}
+
+var queueFields = []queueField{
+	{
+		path: "rate_limits.max_dispatches_per_second",
+		want: func(c QueueConfig) (string, bool) {
+			return formatFloat(c.MaxDispatchesPerSecond), c.MaxDispatchesPerSecond != 0
+		},
+		get: func(q *taskspb.Queue) string {
+			if q.GetRateLimits() == nil {
+				return ""
+			}
+			return formatFloat(q.RateLimits.MaxDispatchesPerSecond)
+		},
+		set: func(q *taskspb.Queue, c QueueConfig) {
+			rateLimits(q).MaxDispatchesPerSecond = c.MaxDispatchesPerSecond
+		},
+	},
+	{
+		path: "rate_limits.max_concurrent_dispatches",
+		want: func(c QueueConfig) (string, bool) {
+			return strconv.Itoa(int(c.MaxConcurrentDispatches)), c.MaxConcurrentDispatches != 0
+		},
+		get: func(q *taskspb.Queue) string {
+			if q.GetRateLimits() == nil {
+				return ""
+			}
+			return strconv.Itoa(int(q.RateLimits.MaxConcurrentDispatches))
+		},
+		set: func(q *taskspb.Queue, c QueueConfig) {
+			rateLimits(q).MaxConcurrentDispatches = c.MaxConcurrentDispatches
+		},
+	},
+	{
+		path: "retry_config.max_attempts",
+		want: func(c QueueConfig) (string, bool) {
+			return strconv.Itoa(int(c.MaxAttempts)), c.MaxAttempts != 0
+		},
+		get: func(q *taskspb.Queue) string {
+			if q.GetRetryConfig() == nil {
+				return ""
+			}
+			return strconv.Itoa(int(q.RetryConfig.MaxAttempts))
+		},
+		set: func(q *taskspb.Queue, c QueueConfig) {
+			retryConfig(q).MaxAttempts = c.MaxAttempts
+		},
+	},
+	durationField("retry_config.min_backoff",
+		func(c QueueConfig) time.Duration { return c.MinBackoff },
+		func(r *taskspb.RetryConfig) **durationpb.Duration { return &r.MinBackoff }),
+	durationField("retry_config.max_backoff",
+		func(c QueueConfig) time.Duration { return c.MaxBackoff },
+		func(r *taskspb.RetryConfig) **durationpb.Duration { return &r.MaxBackoff }),
+	durationField("retry_config.max_retry_duration",
+		func(c QueueConfig) time.Duration { return c.MaxRetryDuration },
+		func(r *taskspb.RetryConfig) **durationpb.Duration { return &r.MaxRetryDuration }),
+	{
+		path: "stackdriver_logging_config.sampling_ratio",
+		want: func(c QueueConfig) (string, bool) {
+			return formatFloat(c.LogSamplingRatio), c.LogSamplingRatio != 0
+		},
+		get: func(q *taskspb.Queue) string {
+			if q.GetStackdriverLoggingConfig() == nil {
+				return ""
+			}
+			return formatFloat(q.StackdriverLoggingConfig.SamplingRatio)
+		},
+		set: func(q *taskspb.Queue, c QueueConfig) {
+			if q.StackdriverLoggingConfig == nil {
+				q.StackdriverLoggingConfig = &taskspb.StackdriverLoggingConfig{}
+			}
+			q.StackdriverLoggingConfig.SamplingRatio = c.LogSamplingRatio
+		},
+	},
+}
+
+// durationField describes a RetryConfig duration setting.
+func durationField(path string, want func(QueueConfig) time.Duration, field func(*taskspb.RetryConfig) **durationpb.Duration) queueField {
+	return queueField{
+		path: path,
+		want: func(c QueueConfig) (string, bool) {
+			return want(c).String(), want(c) != 0
+		},
+		get: func(q *taskspb.Queue) string {
+			if q.GetRetryConfig() == nil || *field(q.RetryConfig) == nil {
+				return ""
+			}
+			return (*field(q.RetryConfig)).AsDuration().String()
+		},
+		set: func(q *taskspb.Queue, c QueueConfig) {
+			*field(retryConfig(q)) = durationpb.New(want(c))
+		},
+	}
+}
+
+// diff returns the managed settings whose value in queue differs from the
+// configuration, after copying the configured values into queue.
+func (c QueueConfig) diff(queue *taskspb.Queue) []QueueChange {
+	var changes []QueueChange
+	for _, field := range queueFields {
+		want, managed := field.want(c)
+		if !managed {
+			continue
+		}
+		current := field.get(queue)
+		if current == want {
+			continue
+		}
+		if current == "" {
+			current = "default"
+		}
+		changes = append(changes, QueueChange{Field: field.path, From: current, To: want})
+		field.set(queue, c)
+	}
+	return changes
+}
+
+func rateLimits(q *taskspb.Queue) *taskspb.RateLimits {
+	if q.RateLimits == nil {
+		q.RateLimits = &taskspb.RateLimits{}
+	}
+	return q.RateLimits
+}
+
+func retryConfig(q *taskspb.Queue) *taskspb.RetryConfig {
+	if q.RetryConfig == nil {
+		q.RetryConfig = &taskspb.RetryConfig{}
+	}
+	return q.RetryConfig
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,178 @@
+package sink
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hibiken/asynq"
+)
+
+// RedisOptions configures a Redis sink.
+type RedisOptions struct {
+	Addr       string // Redis address, host:port
+	Password   string // Redis password; empty for none
+	DB         int    // Redis database number
+	Queue      string // asynq queue the tasks are enqueued on; empty uses "default"
+	TypePrefix string // Prefix of the asynq task types; empty uses "gametask"
+}
+
+// Redis is a TaskSink that enqueues each task's body as a delayed asynq job
+// in Redis, for self-hosted stacks without GCP. Jobs are encoded by the
+// asynq client itself, so any asynq worker can process them: a game task has
+// the type "gametask:track" and other tasks "gametask:<type attribute>". The
+// task name is the asynq task ID, so scheduling the same name twice is
+// rejected. The task's URL, method and headers are not used.
+type Redis struct {
+	options   RedisOptions
+	client    *asynq.Client
+	inspector *asynq.Inspector
+}
+
+// NewRedis creates a sink that enqueues jobs in the Redis at options.Addr.
+func NewRedis(options RedisOptions) *Redis {
+	if options.Queue == "" {
+		options.Queue = "default"
+	}
+	if options.TypePrefix == "" {
+		options.TypePrefix = "gametask"
+	}
+
+	conn := asynq.RedisClientOpt{Addr: options.Addr, Password: options.Password, DB: options.DB}
+	return &Redis{
+		options:   options,
+		client:    asynq.NewClient(conn),
+		inspector: asynq.NewInspector(conn),
+	}
+}
+
+// Check verifies that Redis answers.
+func (r *Redis) Check() error {
+	if _, err := r.inspector.Queues(); err != nil {
+		return fmt.Errorf("failed to reach Redis at %s: %w", r.options.Addr, err)
+	}
+	return nil
+}
+
+// Schedule enqueues the task's body to be processed at its schedule time. A
+// task without a name is named after its gameId and type attributes, or
+// after its body if it has none, and its schedule time, so scheduling the
+// same game again is rejected as a duplicate while a moved game gets a new job.
+func (r *Redis) Schedule(ctx context.Context, task Task) (Task, error) {
+	if err := ctx.Err(); err != nil {
+		return Task{}, err
+	}
+
+	if task.Name == "" {
+		task.Name = redisTaskID(task)
+	}
+	job := asynq.NewTask(r.taskType(task), task.Body)
+	_, err := r.client.EnqueueContext(ctx, job,
+		asynq.TaskID(task.Name),
+		asynq.Queue(r.options.Queue),
+		asynq.ProcessAt(task.ScheduleTime))
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return Task{Name: task.Name}, fmt.Errorf("%w: %s", ErrAlreadyExists, task.Name)
+	}
+	if err != nil {
+		return Task{}, fmt.Errorf("failed to enqueue %s: %w", task.Name, err)
+	}
+
+	task.Method = task.method()
+	return task, nil
+}
+
+// Cancel deletes a job that is still scheduled or pending.
+func (r *Redis) Cancel(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := r.inspector.DeleteTask(r.options.Queue, name)
+	if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to cancel %s: %w", name, err)
+	}
+	return nil
+}
+
+// List returns the scheduled and pending jobs in the queue, ordered by the
+// time they are due.
+func (r *Redis) List(ctx context.Context) ([]Task, error) {
+	var tasks []Task
+	for _, list := range []func(string, ...asynq.ListOption) ([]*asynq.TaskInfo, error){
+		r.inspector.ListScheduledTasks,
+		r.inspector.ListPendingTasks,
+	} {
+		for page := 1; ; page++ {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			infos, err := list(r.options.Queue, asynq.PageSize(100), asynq.Page(page))
+			if errors.Is(err, asynq.ErrQueueNotFound) {
+				// Nothing has been enqueued yet
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tasks: %w", err)
+			}
+			for _, info := range infos {
+				tasks = append(tasks, r.fromTaskInfo(info))
+			}
+			if len(infos) < 100 {
+				break
+			}
+		}
+	}
+	sortTasks(tasks)
+	return tasks, nil
+}
+
+// Close closes the connections to Redis.
+func (r *Redis) Close() error {
+	return errors.Join(r.client.Close(), r.inspector.Close())
+}
+
+// taskType returns the asynq type of a task, from its type attribute.
+func (r *Redis) taskType(task Task) string {
+	kind := task.Attributes["type"]
+	if kind == "" {
+		kind = "track"
+	}
+	return r.options.TypePrefix + ":" + kind
+}
+
+// fromTaskInfo converts an asynq job back into a Task.
+func (r *Redis) fromTaskInfo(info *asynq.TaskInfo) Task {
+	task := Task{
+		Name:         info.ID,
+		Body:         info.Payload,
+		ScheduleTime: info.NextProcessAt,
+	}
+	if kind := strings.TrimPrefix(info.Type, r.options.TypePrefix+":"); kind != "track" {
+		task.Attributes = map[string]string{"type": kind}
+	}
+	task.Method = task.method()
+	return task
+}
+
+// redisTaskID derives a stable ID for an unnamed task, including its schedule
+// time so a rescheduled task does not collide with the job it replaces.
+func redisTaskID(task Task) string {
+	at := strconv.FormatInt(task.ScheduleTime.Unix(), 10)
+	if id := task.Attributes["gameId"]; id != "" {
+		if kind := task.Attributes["type"]; kind != "" {
+			return kind + "-" + id + "-" + at
+		}
+		return "game-" + id + "-" + at
+	}
+	sum := sha256.Sum256(task.Body)
+	return "task-" + hex.EncodeToString(sum[:8]) + "-" + at
+}
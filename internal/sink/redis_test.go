@@ -0,0 +1,143 @@
+package sink
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/hibiken/asynq"
+)
+
+// newTestRedis starts an in-memory Redis and returns a sink connected to it.
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	r := NewRedis(RedisOptions{Addr: server.Addr()})
+	t.Cleanup(func() { r.Close() })
+	return r, server
+}
+
+func TestRedisScheduleEncodesAsynqTask(t *testing.T) {
+	r, server := newTestRedis(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	scheduled, err := r.Schedule(ctx, Task{
+		Body:         []byte(`{"game":{"id":"2024030411"}}`),
+		ScheduleTime: at,
+		Attributes:   map[string]string{"gameId": "2024030411"},
+	})
+	if err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	wantID := "game-2024030411-" + strconv.FormatInt(at.Unix(), 10)
+	if scheduled.Name != wantID {
+		t.Errorf("Name = %q, want it named after the game and schedule time", scheduled.Name)
+	}
+
+	// Read the job back with asynq's own inspector, as a worker would see it
+	inspector := asynq.NewInspector(asynq.RedisClientOpt{Addr: server.Addr()})
+	defer inspector.Close()
+	info, err := inspector.GetTaskInfo("default", wantID)
+	if err != nil {
+		t.Fatalf("GetTaskInfo() returned error: %v", err)
+	}
+	if info.Type != "gametask:track" || info.State != asynq.TaskStateScheduled {
+		t.Errorf("job is %s in state %s, want a scheduled gametask:track", info.Type, info.State)
+	}
+	if string(info.Payload) != `{"game":{"id":"2024030411"}}` {
+		t.Errorf("Payload = %s, want the task body", info.Payload)
+	}
+	if !info.NextProcessAt.Equal(at) {
+		t.Errorf("NextProcessAt = %s, want %s", info.NextProcessAt, at)
+	}
+}
+
+func TestRedisScheduleDuplicateName(t *testing.T) {
+	r, _ := newTestRedis(t)
+	ctx := context.Background()
+	at := time.Now().Add(time.Hour)
+
+	reminder := Task{ScheduleTime: at, Attributes: map[string]string{"gameId": "1", "type": "pregame_reminder"}}
+	if _, err := r.Schedule(ctx, reminder); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	existing, err := r.Schedule(ctx, reminder)
+	if !errors.Is(err, ErrAlreadyExists) {
+		t.Errorf("second Schedule() error = %v, want ErrAlreadyExists", err)
+	}
+	if existing.Name != redisTaskID(reminder) {
+		t.Errorf("second Schedule() name = %q, want the existing job's ID", existing.Name)
+	}
+	if _, err := r.Schedule(ctx, Task{ScheduleTime: at, Attributes: map[string]string{"gameId": "1"}}); err != nil {
+		t.Errorf("Schedule() of the game task returned error: %v, want it distinct from the reminder", err)
+	}
+
+	// A game whose start time moved gets a new job rather than keeping the stale one
+	reminder.ScheduleTime = at.Add(time.Hour)
+	if _, err := r.Schedule(ctx, reminder); err != nil {
+		t.Errorf("Schedule() of the moved reminder returned error: %v, want a new job", err)
+	}
+}
+
+func TestRedisListAndCancel(t *testing.T) {
+	r, _ := newTestRedis(t)
+	ctx := context.Background()
+
+	tasks, err := r.List(ctx)
+	if err != nil || len(tasks) != 0 {
+		t.Fatalf("List() before enqueueing = %v, %v, want no tasks", tasks, err)
+	}
+
+	at := time.Now().Add(time.Hour).Truncate(time.Second)
+	if _, err := r.Schedule(ctx, Task{Name: "late", ScheduleTime: at}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if _, err := r.Schedule(ctx, Task{Name: "reminder", ScheduleTime: at.Add(-time.Hour / 2), Attributes: map[string]string{"type": "pregame_reminder"}}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+	if _, err := r.Schedule(ctx, Task{Name: "due", ScheduleTime: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatalf("Schedule() returned error: %v", err)
+	}
+
+	tasks, err = r.List(ctx)
+	if err != nil {
+		t.Fatalf("List() returned error: %v", err)
+	}
+	var names []string
+	for _, task := range tasks {
+		names = append(names, task.Name)
+	}
+	if len(names) != 3 || names[0] != "due" || names[1] != "reminder" || names[2] != "late" {
+		t.Fatalf("List() names = %v, want [due reminder late]", names)
+	}
+	if tasks[1].Attributes["type"] != "pregame_reminder" {
+		t.Errorf("reminder attributes = %v, want its type", tasks[1].Attributes)
+	}
+
+	if err := r.Cancel(ctx, "late"); err != nil {
+		t.Fatalf("Cancel() returned error: %v", err)
+	}
+	if err := r.Cancel(ctx, "late"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Cancel() error = %v, want ErrNotFound", err)
+	}
+	if tasks, _ := r.List(ctx); len(tasks) != 2 {
+		t.Errorf("List() after Cancel() returned %d tasks, want 2", len(tasks))
+	}
+}
+
+func TestRedisCheck(t *testing.T) {
+	r, server := newTestRedis(t)
+
+	if err := r.Check(); err != nil {
+		t.Errorf("Check() returned error: %v", err)
+	}
+	server.Close()
+	if err := r.Check(); err == nil {
+		t.Error("Check() with Redis down returned nil error")
+	}
+}
@@ -0,0 +1,69 @@
+// Package sink abstracts how scheduled game tasks are delivered. A TaskSink
+// accepts HTTP requests to be made at a later time; Cloud Tasks is one
+// implementation, Pub/Sub another, and an in-memory sink serves dry runs and
+// tests.
+package sink
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// ErrAlreadyExists is returned by Schedule when a task with the same name
+// has already been scheduled.
+var ErrAlreadyExists = errors.New("task already exists")
+
+// ErrNotFound is returned by Cancel when no pending task has the given name.
+var ErrNotFound = errors.New("task not found")
+
+// Task is an HTTP request to deliver at ScheduleTime.
+type Task struct {
+	// Name identifies the task within its sink. Leave it empty to let the
+	// sink assign one; Schedule returns the task with its name set.
+	Name         string            `json:"name"`
+	URL          string            `json:"url"`
+	Method       string            `json:"method,omitempty"` // HTTP method; empty means POST
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         []byte            `json:"body,omitempty"`
+	ScheduleTime time.Time         `json:"scheduleTime"`
+	// Attributes describe the task to sinks that deliver it as a message
+	// rather than a request, such as Pub/Sub; HTTP sinks ignore them.
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// method returns the task's HTTP method, defaulting to POST.
+func (t Task) method() string {
+	if t.Method == "" {
+		return http.MethodPost
+	}
+	return t.Method
+}
+
+// TaskSink schedules, cancels and lists pending tasks.
+type TaskSink interface {
+	// Schedule queues a task for delivery at its ScheduleTime and returns it
+	// with its Name set. It returns an error wrapping ErrAlreadyExists if a
+	// task with the same name was already scheduled, along with a Task holding
+	// the name the sink knows that task by.
+	Schedule(ctx context.Context, task Task) (Task, error)
+
+	// Cancel removes a pending task. It returns an error wrapping ErrNotFound
+	// if no such task is pending.
+	Cancel(ctx context.Context, name string) error
+
+	// List returns the pending tasks ordered by schedule time.
+	List(ctx context.Context) ([]Task, error)
+}
+
+// sortTasks orders tasks by schedule time, then name.
+func sortTasks(tasks []Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if !tasks[i].ScheduleTime.Equal(tasks[j].ScheduleTime) {
+			return tasks[i].ScheduleTime.Before(tasks[j].ScheduleTime)
+		}
+		return tasks[i].Name < tasks[j].Name
+	})
+}
@@ -0,0 +1,77 @@
+package sink
+
+import "time"
+
+// timerWheel is a hashed timing wheel. Each slot covers one tick; entries due
+// more than one rotation ahead carry the number of rotations left, so any
+// schedule time fits in a fixed number of slots. It is not safe for
+// concurrent use.
+type timerWheel struct {
+	tick    time.Duration
+	slots   []map[string]int // task name -> rotations left
+	index   map[string]int   // task name -> slot
+	pos     int              // next slot to expire
+	current time.Time        // start of slot pos
+}
+
+// newTimerWheel creates a wheel of size slots of one tick each, starting at start.
+func newTimerWheel(tick time.Duration, size int, start time.Time) *timerWheel {
+	slots := make([]map[string]int, size)
+	for i := range slots {
+		slots[i] = make(map[string]int)
+	}
+	return &timerWheel{
+		tick:    tick,
+		slots:   slots,
+		index:   make(map[string]int),
+		current: start.Truncate(tick),
+	}
+}
+
+// add schedules name to expire at the first tick at or after at. Times in the
+// past expire on the next advance. Adding a name again reschedules it.
+func (w *timerWheel) add(name string, at time.Time) {
+	w.remove(name)
+
+	ticks := 0
+	if d := at.Sub(w.current); d > 0 {
+		ticks = int((d + w.tick - 1) / w.tick)
+	}
+	slot := (w.pos + ticks) % len(w.slots)
+	w.slots[slot][name] = ticks / len(w.slots)
+	w.index[name] = slot
+}
+
+// remove unschedules name, if present.
+func (w *timerWheel) remove(name string) {
+	if slot, ok := w.index[name]; ok {
+		delete(w.slots[slot], name)
+		delete(w.index, name)
+	}
+}
+
+// len returns the number of scheduled names.
+func (w *timerWheel) len() int {
+	return len(w.index)
+}
+
+// advance expires every slot that starts at or before now and returns the
+// names that came due.
+func (w *timerWheel) advance(now time.Time) []string {
+	var due []string
+	for !w.current.After(now) {
+		slot := w.slots[w.pos]
+		for name, rotations := range slot {
+			if rotations == 0 {
+				due = append(due, name)
+				delete(slot, name)
+				delete(w.index, name)
+			} else {
+				slot[name] = rotations - 1
+			}
+		}
+		w.pos = (w.pos + 1) % len(w.slots)
+		w.current = w.current.Add(w.tick)
+	}
+	return due
+}
@@ -0,0 +1,69 @@
+package sink
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTimerWheelExpiresInOrder(t *testing.T) {
+	start := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+	w := newTimerWheel(time.Second, 10, start)
+
+	w.add("past", start.Add(-time.Hour))
+	w.add("soon", start.Add(3*time.Second))
+	w.add("later", start.Add(25*time.Second)) // more than two rotations ahead
+
+	if got := w.advance(start); len(got) != 1 || got[0] != "past" {
+		t.Errorf("advance(start) = %v, want [past]", got)
+	}
+	if got := w.advance(start.Add(2 * time.Second)); len(got) != 0 {
+		t.Errorf("advance(+2s) = %v, want nothing due", got)
+	}
+	if got := w.advance(start.Add(3 * time.Second)); len(got) != 1 || got[0] != "soon" {
+		t.Errorf("advance(+3s) = %v, want [soon]", got)
+	}
+	if got := w.advance(start.Add(24 * time.Second)); len(got) != 0 {
+		t.Errorf("advance(+24s) = %v, want nothing due", got)
+	}
+	if got := w.advance(start.Add(25 * time.Second)); len(got) != 1 || got[0] != "later" {
+		t.Errorf("advance(+25s) = %v, want [later]", got)
+	}
+	if w.len() != 0 {
+		t.Errorf("len() = %d, want 0", w.len())
+	}
+}
+
+func TestTimerWheelNeverFiresEarly(t *testing.T) {
+	start := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+	w := newTimerWheel(time.Second, 4, start)
+
+	at := start.Add(1500 * time.Millisecond)
+	w.add("task", at)
+
+	if got := w.advance(start.Add(1 * time.Second)); len(got) != 0 {
+		t.Errorf("advance before due = %v, want nothing due", got)
+	}
+	if got := w.advance(start.Add(2 * time.Second)); len(got) != 1 {
+		t.Errorf("advance after due = %v, want [task]", got)
+	}
+}
+
+func TestTimerWheelRemoveAndReschedule(t *testing.T) {
+	start := time.Date(2025, 1, 15, 19, 0, 0, 0, time.UTC)
+	w := newTimerWheel(time.Second, 10, start)
+
+	w.add("a", start.Add(time.Second))
+	w.add("b", start.Add(time.Second))
+	w.remove("a")
+	w.add("b", start.Add(5*time.Second))
+
+	if got := w.advance(start.Add(4 * time.Second)); len(got) != 0 {
+		t.Errorf("advance(+4s) = %v, want nothing due", got)
+	}
+	got := w.advance(start.Add(10 * time.Second))
+	sort.Strings(got)
+	if len(got) != 1 || got[0] != "b" {
+		t.Errorf("advance(+10s) = %v, want [b]", got)
+	}
+}
@@ -0,0 +1,126 @@
+// Package tracing configures OpenTelemetry tracing for scheduling runs and
+// propagates the trace context into scheduled tasks, so the tracker's spans
+// continue the trace that scheduled them.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// instrumentationName identifies the tracer that creates the run's own spans.
+const instrumentationName = "github.com/CrashTheCrease/backend/gameTaskEmulator"
+
+// Options configures the OTLP exporter.
+type Options struct {
+	Endpoint    string // OTLP gRPC collector address (host:port); empty falls back to OTEL_EXPORTER_OTLP_ENDPOINT
+	Insecure    bool   // Connect to the collector without TLS, e.g. a local collector
+	ServiceName string // service.name resource attribute
+	RunID       string // run.id resource attribute
+}
+
+// Provider owns the tracer provider for the run. The zero value, used when
+// no collector is configured, records nothing.
+type Provider struct {
+	provider *sdktrace.TracerProvider
+}
+
+// Setup installs the W3C trace-context propagator and, when a collector is
+// configured, a tracer provider exporting spans over OTLP. Without a
+// collector, spans are not recorded but incoming context still propagates.
+func Setup(ctx context.Context, options Options) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if options.Endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return &Provider{}, nil
+	}
+
+	var clientOptions []otlptracegrpc.Option
+	if options.Endpoint != "" {
+		clientOptions = append(clientOptions, otlptracegrpc.WithEndpoint(options.Endpoint))
+	}
+	if options.Insecure {
+		clientOptions = append(clientOptions, otlptracegrpc.WithInsecure())
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	attrs := []attribute.KeyValue{attribute.String("service.name", options.ServiceName)}
+	if options.RunID != "" {
+		attrs = append(attrs, attribute.String("run.id", options.RunID))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attrs...)),
+	)
+	otel.SetTracerProvider(provider)
+
+	return &Provider{provider: provider}, nil
+}
+
+// Enabled reports whether spans are exported.
+func (p *Provider) Enabled() bool {
+	return p != nil && p.provider != nil
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if !p.Enabled() {
+		return nil
+	}
+	return p.provider.Shutdown(ctx)
+}
+
+// Tracer returns the tracer for the run's own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// RecordError marks the span as failed with err; a nil err is ignored.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// Inject writes the trace context of ctx (traceparent, tracestate and
+// baggage) into headers.
+func Inject(ctx context.Context, headers map[string]string) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(headers))
+}
+
+// HTTPClient returns a copy of client whose requests are traced.
+func HTTPClient(client *http.Client) *http.Client {
+	traced := *client
+	transport := traced.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	traced.Transport = otelhttp.NewTransport(transport)
+	return &traced
+}
+
+// DialOption traces every RPC made on a gRPC client connection.
+func DialOption() grpc.DialOption {
+	return grpc.WithStatsHandler(otelgrpc.NewClientHandler())
+}
@@ -0,0 +1,141 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newTestProvider installs an in-memory tracer provider for the duration of the test.
+func newTestProvider(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func TestSetupWithoutEndpointIsDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+
+	p, err := Setup(context.Background(), Options{ServiceName: "test"})
+	if err != nil {
+		t.Fatalf("Setup() returned error: %v", err)
+	}
+	if p.Enabled() {
+		t.Error("Enabled() = true, want false without an endpoint")
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() returned error: %v", err)
+	}
+}
+
+func TestInjectWritesTraceparent(t *testing.T) {
+	newTestProvider(t)
+
+	ctx, span := Tracer().Start(context.Background(), "run")
+	defer span.End()
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	Inject(ctx, headers)
+
+	traceparent := headers["traceparent"]
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Errorf("traceparent = %q, want trace ID %s", traceparent, span.SpanContext().TraceID())
+	}
+	if headers["Content-Type"] != "application/json" {
+		t.Error("Inject() overwrote existing headers")
+	}
+}
+
+func TestInjectWithoutSpan(t *testing.T) {
+	newTestProvider(t)
+
+	headers := map[string]string{}
+	Inject(context.Background(), headers)
+
+	if _, ok := headers["traceparent"]; ok {
+		t.Error("Inject() wrote traceparent without an active span")
+	}
+}
+
+func TestHTTPClientPropagatesContext(t *testing.T) {
+	exporter := newTestProvider(t)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+	}))
+	defer server.Close()
+
+	ctx, span := Tracer().Start(context.Background(), "run")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	resp, err := HTTPClient(server.Client()).Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	span.End()
+
+	if !strings.Contains(traceparent, span.SpanContext().TraceID().String()) {
+		t.Errorf("server saw traceparent %q, want trace ID %s", traceparent, span.SpanContext().TraceID())
+	}
+	if got := len(exporter.GetSpans()); got != 2 {
+		t.Errorf("recorded %d spans, want 2 (run and HTTP client)", got)
+	}
+}
+
+func TestHTTPClientLeavesOriginalUntouched(t *testing.T) {
+	client := &http.Client{}
+	traced := HTTPClient(client)
+
+	if client.Transport != nil {
+		t.Error("HTTPClient() modified the original client's transport")
+	}
+	if traced.Transport == nil {
+		t.Error("HTTPClient() did not set a traced transport")
+	}
+}
+
+func TestRecordError(t *testing.T) {
+	exporter := newTestProvider(t)
+
+	_, failed := Tracer().Start(context.Background(), "failed")
+	RecordError(failed, errors.New("boom"))
+	failed.End()
+
+	_, ok := Tracer().Start(context.Background(), "ok")
+	RecordError(ok, nil)
+	ok.End()
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+	if spans[0].Status.Code != codes.Error || spans[0].Status.Description != "boom" {
+		t.Errorf("failed span status = %+v, want Error \"boom\"", spans[0].Status)
+	}
+	if spans[1].Status.Code != codes.Unset {
+		t.Errorf("ok span status = %+v, want Unset", spans[1].Status)
+	}
+}
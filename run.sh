@@ -6,6 +6,10 @@ set -e
 
 # Configuration
 IMAGE="${DOCKER_IMAGE:-blnelson/firepowergametaskemulator:latest}"
+# Host directory kept across runs for the scheduling history database
+DATA_DIR="${GAMETASK_DATA_DIR:-${STATE_DIRECTORY:-${HOME}/.local/share/gameTaskEmulator}}"
+# Non-root user the image runs as (appuser in the Dockerfile)
+IMAGE_USER="1000:1000"
 FORCE_PULL=false
 
 # Colors for output
@@ -127,6 +131,25 @@ if [ -n "${GOOGLE_APPLICATION_CREDENTIALS}" ]; then
     fi
 fi
 
+# Keep the scheduling history on the host; the container is removed after
+# every run. The container never runs as root: GAMETASK_CONTAINER_USER wins,
+# a non-root caller runs it as themselves so the mounted directory is
+# writable, and root (cron, systemd without User=) hands the directory to the
+# image's own non-root user instead.
+mkdir -p "${DATA_DIR}"
+if [ -n "${GAMETASK_CONTAINER_USER}" ]; then
+    CONTAINER_USER="${GAMETASK_CONTAINER_USER}"
+elif [ "$(id -u)" -ne 0 ]; then
+    CONTAINER_USER="$(id -u):$(id -g)"
+else
+    CONTAINER_USER="${IMAGE_USER}"
+    chown "${CONTAINER_USER}" "${DATA_DIR}"
+fi
+RUN_ARGS+=(-v "${DATA_DIR}:/data")
+RUN_ARGS+=(-e "HISTORY_DB=/data/gametask-history.db")
+RUN_ARGS+=(--user "${CONTAINER_USER}")
+log_info "Keeping scheduling history in ${DATA_DIR} (container user ${CONTAINER_USER})"
+
 # Set timezone to host timezone
 RUN_ARGS+=(-e "TZ=$(cat /etc/timezone 2>/dev/null || echo 'UTC')")
 